@@ -3,6 +3,7 @@ package main
 import (
 	"context"
 	"log/slog"
+	"net"
 	"net/http"
 	"os"
 
@@ -61,10 +62,15 @@ func startServers(logger *slog.Logger) (*echo.Echo, *echo.Echo) {
 
 		r, _ := http.NewRequestWithContext(ctx, http.MethodGet, "http://localhost:8132/", nil)
 		doer := strc.NewTracingDoer(http.DefaultClient)
-		doer.Do(r)
+		if res, err := doer.Do(r); err == nil {
+			// the client span ends when the body is closed
+			res.Body.Close()
+		}
 		return c.String(200, "ok")
 	})
-	go s1.Start(":8131")
+	// listen before returning so the first request does not race the server start
+	s1.Listener, _ = net.Listen("tcp", ":8131")
+	go s1.Start("")
 
 	s2 := echo.New()
 	s2.HideBanner = true
@@ -82,7 +88,8 @@ func startServers(logger *slog.Logger) (*echo.Echo, *echo.Echo) {
 		subProcess(ctx)
 		return c.String(200, "ok")
 	})
-	go s2.Start(":8132")
+	s2.Listener, _ = net.Listen("tcp", ":8132")
+	go s2.Start("")
 
 	return s1, s2
 }
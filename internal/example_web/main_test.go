@@ -16,16 +16,17 @@ func TestMain(t *testing.T) {
 	req.Header.Set("X-Request-Id", "12345")
 	request(req, logs)
 
-	require.Equal(t, 18, logs.Count())
+	require.Equal(t, 22, logs.Count())
 
-	require.Equal(t, 18, logs.CountWith("build_id"))
+	require.Equal(t, 22, logs.CountWith("build_id"))
 	require.Equal(t, 5, logs.CountWith("request_id"))
 	require.Equal(t, 6, logs.CountWith("trace_id"))
 	require.Equal(t, 1, logs.CountWith("logrus"))
 	require.Equal(t, 2, logs.CountWith("echo"))
 
-	require.Equal(t, 12, logs.CountWith("span", "trace"))
-	require.Equal(t, 12, logs.CountWith("span", "id"))
+	require.Equal(t, 16, logs.CountWith("span", "trace"))
+	require.Equal(t, 16, logs.CountWith("span", "id"))
+	require.Equal(t, 4, logs.CountWith("span", "route"))
 
 	require.Equal(t, 2, logs.CountWith("request", "method"))
 	require.Equal(t, 2, logs.CountWith("request", "path"))
@@ -36,7 +37,7 @@ func TestMain(t *testing.T) {
 
 	tids := logs.CollectWith("trace_id")
 	tids = append(tids, logs.CollectWith("span", "trace")...)
-	require.Len(t, tids, 18)
+	require.Len(t, tids, 22)
 	for _, v := range tids {
 		require.Equal(t, tids[0], v)
 	}
@@ -0,0 +1,47 @@
+package collect
+
+import (
+	"sync"
+	"time"
+)
+
+// FakeClock is a deterministic clock for tests, it implements strc.Clock. Every call to Now
+// returns the current time and advances the clock by the step, so span durations are
+// predictable without manual advancing.
+type FakeClock struct {
+	mu   sync.Mutex
+	now  time.Time
+	step time.Duration
+}
+
+// NewFakeClock creates a new fake clock starting at the given time. Step can be zero, use
+// Advance to move the clock manually.
+func NewFakeClock(start time.Time, step time.Duration) *FakeClock {
+	return &FakeClock{now: start, step: step}
+}
+
+// Now returns the current time of the clock and advances it by the step.
+func (c *FakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now
+	c.now = c.now.Add(c.step)
+	return now
+}
+
+// Advance moves the clock forward by the duration.
+func (c *FakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.now = c.now.Add(d)
+}
+
+// Set sets the current time of the clock.
+func (c *FakeClock) Set(t time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.now = t
+}
@@ -0,0 +1,23 @@
+package collect
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFakeClock(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	c := NewFakeClock(start, time.Second)
+
+	if now := c.Now(); !now.Equal(start) {
+		t.Errorf("unexpected time %v", now)
+	}
+	c.Advance(time.Minute)
+	if now := c.Now(); !now.Equal(start.Add(time.Minute + time.Second)) {
+		t.Errorf("unexpected time %v", now)
+	}
+	c.Set(start)
+	if now := c.Now(); !now.Equal(start) {
+		t.Errorf("unexpected time %v", now)
+	}
+}
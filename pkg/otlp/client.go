@@ -0,0 +1,86 @@
+package otlp
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/hashicorp/go-retryablehttp"
+)
+
+// ErrResponseNotOK is returned when the response from the collector is not 2xx.
+var ErrResponseNotOK = errors.New("unexpected response from OTLP collector")
+
+// client sends OTLP/HTTP JSON requests with retries and optional gzip compression
+type client struct {
+	client   *http.Client
+	url      string
+	headers  map[string]string
+	compress bool
+}
+
+func newClient(url string, headers map[string]string, compress bool) *client {
+	rcl := retryablehttp.NewClient()
+	rcl.RetryWaitMin = 300 * time.Millisecond
+	rcl.RetryWaitMax = 3 * time.Second
+	rcl.RetryMax = 5
+	rcl.Logger = log.New(io.Discard, "", 0)
+
+	return &client{
+		client:   rcl.StandardClient(),
+		url:      url,
+		headers:  headers,
+		compress: compress,
+	}
+}
+
+func (c *client) send(ctx context.Context, payload any) error {
+	buf := &bytes.Buffer{}
+	var w io.Writer = buf
+	var zw *gzip.Writer
+	if c.compress {
+		zw = gzip.NewWriter(buf)
+		w = zw
+	}
+
+	if err := json.NewEncoder(w).Encode(payload); err != nil {
+		return err
+	}
+	if zw != nil {
+		if err := zw.Close(); err != nil {
+			return err
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, buf)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.compress {
+		req.Header.Set("Content-Encoding", "gzip")
+	}
+	for k, v := range c.headers {
+		req.Header.Set(k, v)
+	}
+
+	res, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	_, _ = io.Copy(io.Discard, res.Body)
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return fmt.Errorf("%w: %s", ErrResponseNotOK, res.Status)
+	}
+
+	return nil
+}
@@ -0,0 +1,95 @@
+package otlp
+
+import (
+	"fmt"
+	"log/slog"
+	"strconv"
+	"time"
+)
+
+// OTLP JSON encoding, see https://opentelemetry.io/docs/specs/otlp/#json-protobuf-encoding
+// Trace and span IDs are hex strings and 64-bit integers are decimal strings.
+
+type resource struct {
+	Attributes []keyValue `json:"attributes"`
+}
+
+type scope struct {
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"`
+}
+
+type keyValue struct {
+	Key   string   `json:"key"`
+	Value anyValue `json:"value"`
+}
+
+type anyValue struct {
+	StringValue *string      `json:"stringValue,omitempty"`
+	BoolValue   *bool        `json:"boolValue,omitempty"`
+	IntValue    *string      `json:"intValue,omitempty"`
+	DoubleValue *float64     `json:"doubleValue,omitempty"`
+	KvlistValue *kvlistValue `json:"kvlistValue,omitempty"`
+}
+
+type kvlistValue struct {
+	Values []keyValue `json:"values"`
+}
+
+// ScopeName is the instrumentation scope name of exported data.
+const ScopeName = "github.com/osbuild/logging/pkg/strc"
+
+func stringValue(s string) anyValue {
+	return anyValue{StringValue: &s}
+}
+
+func intValue(i int64) anyValue {
+	s := strconv.FormatInt(i, 10)
+	return anyValue{IntValue: &s}
+}
+
+func unixNano(t time.Time) string {
+	return strconv.FormatInt(t.UnixNano(), 10)
+}
+
+// convertAttrs converts slog attributes to OTLP key-values, groups are converted to key-value
+// lists and empty attributes are skipped
+func convertAttrs(attrs []slog.Attr) []keyValue {
+	result := make([]keyValue, 0, len(attrs))
+	for _, a := range attrs {
+		if a.Equal(slog.Attr{}) {
+			continue
+		}
+
+		result = append(result, keyValue{Key: a.Key, Value: convertValue(a.Value)})
+	}
+
+	return result
+}
+
+func convertValue(v slog.Value) anyValue {
+	v = v.Resolve()
+	switch v.Kind() {
+	case slog.KindString:
+		return stringValue(v.String())
+	case slog.KindInt64:
+		return intValue(v.Int64())
+	case slog.KindUint64:
+		s := strconv.FormatUint(v.Uint64(), 10)
+		return anyValue{IntValue: &s}
+	case slog.KindFloat64:
+		f := v.Float64()
+		return anyValue{DoubleValue: &f}
+	case slog.KindBool:
+		b := v.Bool()
+		return anyValue{BoolValue: &b}
+	case slog.KindDuration:
+		return stringValue(v.Duration().String())
+	case slog.KindTime:
+		return stringValue(v.Time().Format(time.RFC3339Nano))
+	case slog.KindGroup:
+		return anyValue{KvlistValue: &kvlistValue{Values: convertAttrs(v.Group())}}
+	default:
+		return stringValue(fmt.Sprint(v.Any()))
+	}
+}
@@ -0,0 +1,464 @@
+package otlp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/osbuild/logging/pkg/strc"
+)
+
+var _ slog.Handler = (*LogHandler)(nil)
+
+const (
+	// DefaultQueueSize is the size of the channel that holds log records, default 4k.
+	DefaultQueueSize = 4096
+
+	// DefaultBatchSize is the maximum number of log records sent in one request, default 512.
+	DefaultBatchSize = 512
+
+	// DefaultSendFrequency is the frequency at which log records are sent at a maximum, default 5s.
+	DefaultSendFrequency = 5 * time.Second
+)
+
+// LogHandlerConfig is the configuration for the OTLP logs handler.
+type LogHandlerConfig struct {
+	// Level is the minimum level of logs that will be sent.
+	Level slog.Level
+
+	// URL is the OTLP/HTTP logs endpoint, for example http://localhost:4318/v1/logs.
+	URL string
+
+	// Headers are additional HTTP headers, for example authorization.
+	Headers map[string]string
+
+	// ServiceName is the service.name resource attribute.
+	ServiceName string
+
+	// ResourceAttributes are additional resource attributes.
+	ResourceAttributes []slog.Attr
+
+	// DisableCompression turns off gzip compression of requests.
+	DisableCompression bool
+
+	// BatchSize is the maximum number of log records sent in one request, default is DefaultBatchSize.
+	BatchSize int
+
+	// SendFrequency is the frequency at which log records are sent, default is DefaultSendFrequency.
+	SendFrequency time.Duration
+
+	// TraceIDFieldKey is the key of the trace ID field added by strc.MultiHandler, it is
+	// converted to the record trace ID. It must match strc.TracerOptions of the multi handler,
+	// default is strc.TraceIDFieldKey.
+	TraceIDFieldKey string
+}
+
+// Stats are statistics of the OTLP logs handler.
+type Stats struct {
+	// Total number of log records sent
+	RecordCount uint64
+
+	// Total number of requests sent
+	BatchCount uint64
+
+	// Total number of failed requests
+	ErrorCount uint64
+
+	// Total number of log records handled while the handler was open, including records dropped
+	// because the queue was full. Records are added to RecordCount only after their batch was
+	// sent successfully, so RecordCount <= RecordsEnqueued.
+	RecordsEnqueued uint64
+
+	// Last request duration
+	LastRequestDuration time.Duration
+}
+
+// ErrFullOrClosed is returned when the queue is full or closed via Close.
+var ErrFullOrClosed = errors.New("cannot enqueue log record: channel full or closed")
+
+// ErrCloseTimeout is returned when the handler was not closed within the timeout.
+var ErrCloseTimeout = errors.New("close timeout reached")
+
+// LogHandler sends records to an OpenTelemetry collector via OTLP/HTTP with JSON encoding.
+// Records are sent asynchronously in batches. Levels are mapped to OTLP severity numbers,
+// groups to nested attributes and trace_id attribute added by strc.MultiHandler to the trace
+// ID field of the log record.
+type LogHandler struct {
+	level      slog.Level
+	logger     *otlpLogger
+	attrs      []slog.Attr
+	groups     []string
+	traceIDKey string
+}
+
+// NewLogHandler creates a new LogHandler and starts the background sending goroutine which
+// stops when the context is cancelled.
+func NewLogHandler(ctx context.Context, config LogHandlerConfig) *LogHandler {
+	if config.TraceIDFieldKey == "" {
+		config.TraceIDFieldKey = strc.TraceIDFieldKey
+	}
+
+	return &LogHandler{
+		level:      config.Level,
+		logger:     newOTLPLogger(ctx, config),
+		traceIDKey: config.TraceIDFieldKey,
+	}
+}
+
+// Flush flushes all pending records. This is done automatically and it is not necessary to call
+// this method unless you want to force the flush manually (e.g. in an unit test). Calling this
+// method does not guarantee immediate delivery of the records.
+func (h *LogHandler) Flush() {
+	h.logger.flush()
+}
+
+// Close flushes all pending records and stops the handler. Sending new logs after closing the
+// handler will return ErrFullOrClosed. The call can block but not longer than 2 seconds. Use
+// CloseWithTimeout to specify a custom timeout.
+func (h *LogHandler) Close() error {
+	return h.logger.close(2 * time.Second)
+}
+
+// CloseWithTimeout flushes all pending records and stops the handler. The call can block
+// but not longer than the specified timeout.
+//
+// Returns ErrCloseTimeout if the timeout was reached.
+func (h *LogHandler) CloseWithTimeout(timeout time.Duration) error {
+	return h.logger.close(timeout)
+}
+
+// Statistics returns the statistics of the handler.
+func (h *LogHandler) Statistics() Stats {
+	return h.logger.statistics()
+}
+
+func (h *LogHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return level >= h.level
+}
+
+func (h *LogHandler) Handle(ctx context.Context, r slog.Record) error {
+	lr := logRecord{
+		TimeUnixNano:         unixNano(r.Time),
+		ObservedTimeUnixNano: unixNano(time.Now()),
+		SeverityNumber:       severityNumber(r.Level),
+		SeverityText:         r.Level.String(),
+		Body:                 stringValue(r.Message),
+	}
+	if r.Time.IsZero() {
+		lr.TimeUnixNano = lr.ObservedTimeUnixNano
+	}
+
+	tid := strc.TraceIDFromContext(ctx)
+	attrs := slices.Clone(h.attrs)
+	r.Attrs(func(a slog.Attr) bool {
+		if h.traceIDKey != "" && a.Key == h.traceIDKey {
+			tid = strc.TraceID(a.Value.String())
+			return true
+		}
+
+		attrs = mergeAttr(attrs, nestAttr(h.groups, a))
+		return true
+	})
+	lr.Attributes = convertAttrs(attrs)
+	lr.TraceID = tid.W3C()
+	if lr.TraceID != "" {
+		lr.SpanID = strc.SpanIDFromContext(ctx).W3C()
+	}
+
+	err := h.logger.enqueue(lr)
+
+	// Since errors are silently ignored in slog, let's make an good will attempt.
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "otlp handler error: %v\n", err)
+	}
+
+	return err
+}
+
+func (h *LogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	newAttrs := slices.Clone(h.attrs)
+	for _, a := range attrs {
+		newAttrs = mergeAttr(newAttrs, nestAttr(h.groups, a))
+	}
+
+	return &LogHandler{
+		level:      h.level,
+		logger:     h.logger,
+		attrs:      newAttrs,
+		groups:     h.groups,
+		traceIDKey: h.traceIDKey,
+	}
+}
+
+func (h *LogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+
+	return &LogHandler{
+		level:      h.level,
+		logger:     h.logger,
+		attrs:      h.attrs,
+		groups:     append(slices.Clip(h.groups), name),
+		traceIDKey: h.traceIDKey,
+	}
+}
+
+// severityNumber maps slog levels to OTLP severity numbers, both use steps of four: debug is 5,
+// info is 9, warn is 13 and error is 17
+func severityNumber(level slog.Level) int {
+	return min(max(int(level)+9, 1), 24)
+}
+
+// nestAttr wraps the attribute into groups
+func nestAttr(groups []string, a slog.Attr) slog.Attr {
+	for i := len(groups) - 1; i >= 0; i-- {
+		a = slog.Attr{Key: groups[i], Value: slog.GroupValue(a)}
+	}
+
+	return a
+}
+
+// mergeAttr appends the attribute, groups with the same key are merged and other attributes
+// with the same key are replaced
+func mergeAttr(attrs []slog.Attr, a slog.Attr) []slog.Attr {
+	a.Value = a.Value.Resolve()
+	if a.Key == "" && a.Value.Kind() == slog.KindGroup {
+		// inline group
+		for _, ga := range a.Value.Group() {
+			attrs = mergeAttr(attrs, ga)
+		}
+		return attrs
+	}
+
+	for i := range attrs {
+		if attrs[i].Key != a.Key {
+			continue
+		}
+
+		if attrs[i].Value.Kind() == slog.KindGroup && a.Value.Kind() == slog.KindGroup {
+			merged := slices.Clone(attrs[i].Value.Group())
+			for _, ga := range a.Value.Group() {
+				merged = mergeAttr(merged, ga)
+			}
+			attrs[i] = slog.Attr{Key: a.Key, Value: slog.GroupValue(merged...)}
+		} else {
+			attrs[i] = a
+		}
+		return attrs
+	}
+
+	return append(attrs, a)
+}
+
+type exportLogsServiceRequest struct {
+	ResourceLogs []resourceLogs `json:"resourceLogs"`
+}
+
+type resourceLogs struct {
+	Resource  resource    `json:"resource"`
+	ScopeLogs []scopeLogs `json:"scopeLogs"`
+}
+
+type scopeLogs struct {
+	Scope      scope       `json:"scope"`
+	LogRecords []logRecord `json:"logRecords"`
+}
+
+type logRecord struct {
+	TimeUnixNano         string     `json:"timeUnixNano"`
+	ObservedTimeUnixNano string     `json:"observedTimeUnixNano"`
+	SeverityNumber       int        `json:"severityNumber"`
+	SeverityText         string     `json:"severityText"`
+	Body                 anyValue   `json:"body"`
+	Attributes           []keyValue `json:"attributes,omitempty"`
+	TraceID              string     `json:"traceId,omitempty"`
+	SpanID               string     `json:"spanId,omitempty"`
+}
+
+// otlpLogger batches log records and sends them in a background goroutine
+type otlpLogger struct {
+	client   *client
+	resource resource
+
+	records   chan logRecord
+	active    atomic.Bool
+	closeOnce sync.Once
+	closeMu   sync.RWMutex
+	closed    bool
+
+	batchSize     int
+	sendFrequency time.Duration
+
+	stats   Stats
+	statsMu sync.Mutex
+}
+
+// flushRecord is a marker sent through the channel to request a flush
+var flushRecord = logRecord{}
+
+func newOTLPLogger(ctx context.Context, config LogHandlerConfig) *otlpLogger {
+	l := &otlpLogger{
+		client:        newClient(config.URL, config.Headers, !config.DisableCompression),
+		resource:      newResource(config.ServiceName, config.ResourceAttributes),
+		records:       make(chan logRecord, DefaultQueueSize),
+		batchSize:     DefaultBatchSize,
+		sendFrequency: DefaultSendFrequency,
+	}
+
+	if config.BatchSize > 0 {
+		l.batchSize = config.BatchSize
+	}
+	if config.SendFrequency > 0 {
+		l.sendFrequency = config.SendFrequency
+	}
+
+	ticker := time.NewTicker(l.sendFrequency)
+	l.active.Store(true)
+	go l.sendRecords(ctx, ticker)
+
+	return l
+}
+
+func (l *otlpLogger) statistics() Stats {
+	l.statsMu.Lock()
+	defer l.statsMu.Unlock()
+
+	return l.stats
+}
+
+func (l *otlpLogger) sendRecords(ctx context.Context, ticker *time.Ticker) {
+	defer l.active.Store(false)
+	defer ticker.Stop()
+
+	batch := make([]logRecord, 0, l.batchSize)
+	send := func() {
+		if err := l.send(batch); err != nil {
+			fmt.Fprintf(os.Stderr, "otlp logger unable to send records: %v\n", err)
+		}
+		clear(batch)
+		batch = batch[:0]
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			send()
+			return
+		case r, ok := <-l.records:
+			// close call
+			if !ok {
+				send()
+				return
+			}
+
+			// flush call
+			if r.TimeUnixNano == "" {
+				send()
+				continue
+			}
+
+			batch = append(batch, r)
+			if len(batch) >= l.batchSize {
+				send()
+			}
+		case <-ticker.C:
+			send()
+		}
+	}
+}
+
+func (l *otlpLogger) send(batch []logRecord) error {
+	if len(batch) == 0 {
+		return nil
+	}
+
+	start := time.Now()
+	err := l.client.send(context.Background(), exportLogsServiceRequest{
+		ResourceLogs: []resourceLogs{{
+			Resource: l.resource,
+			ScopeLogs: []scopeLogs{{
+				Scope:      scope{Name: ScopeName},
+				LogRecords: batch,
+			}},
+		}},
+	})
+	dur := time.Since(start)
+
+	l.statsMu.Lock()
+	defer l.statsMu.Unlock()
+	if err != nil {
+		l.stats.ErrorCount++
+		return err
+	}
+	l.stats.RecordCount += uint64(len(batch))
+	l.stats.BatchCount++
+	l.stats.LastRequestDuration = dur
+
+	return nil
+}
+
+// flush will cause the logger to flush the current batch. It does not block, there is no
+// guarantee that the batch will be sent immediately.
+func (l *otlpLogger) flush() {
+	l.closeMu.RLock()
+	defer l.closeMu.RUnlock()
+
+	if !l.closed {
+		select {
+		case l.records <- flushRecord:
+		default:
+		}
+	}
+}
+
+// close will flush the batch, close the channel and wait until all records are sent, not
+// longer than the timeout. It is safe to call close multiple times.
+func (l *otlpLogger) close(timeout time.Duration) error {
+	var result error
+	l.closeOnce.Do(func() {
+		l.closeMu.Lock()
+		l.closed = true
+		close(l.records)
+		l.closeMu.Unlock()
+		deadline := time.Now().Add(timeout)
+		for l.active.Load() {
+			time.Sleep(10 * time.Millisecond)
+
+			if time.Now().After(deadline) {
+				result = ErrCloseTimeout
+				return
+			}
+		}
+	})
+
+	return result
+}
+
+// enqueue sends the record to the channel, it does not block.
+func (l *otlpLogger) enqueue(r logRecord) error {
+	l.closeMu.RLock()
+	defer l.closeMu.RUnlock()
+
+	if l.closed {
+		return ErrFullOrClosed
+	}
+
+	l.statsMu.Lock()
+	l.stats.RecordsEnqueued++
+	l.statsMu.Unlock()
+
+	select {
+	case l.records <- r:
+	default:
+		return ErrFullOrClosed
+	}
+
+	return nil
+}
@@ -0,0 +1,179 @@
+package otlp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/osbuild/logging/pkg/strc"
+)
+
+func logCollector(t *testing.T, records chan<- map[string]any) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		m := map[string]any{}
+		if err := json.NewDecoder(r.Body).Decode(&m); err != nil {
+			t.Error(err)
+		}
+
+		rl := m["resourceLogs"].([]any)[0].(map[string]any)
+		for _, lr := range rl["scopeLogs"].([]any)[0].(map[string]any)["logRecords"].([]any) {
+			records <- lr.(map[string]any)
+		}
+	}))
+}
+
+func TestLogHandler(t *testing.T) {
+	records := make(chan map[string]any, 10)
+	srv := logCollector(t, records)
+	defer srv.Close()
+
+	h := NewLogHandler(context.Background(), LogHandlerConfig{
+		Level:              slog.LevelDebug,
+		URL:                srv.URL,
+		ServiceName:        "test",
+		DisableCompression: true,
+		SendFrequency:      time.Hour,
+	})
+	logger := slog.New(strc.NewMultiHandler(h))
+
+	ctx := strc.WithTraceID(context.Background(), "bqzcRlJahlbbBZH")
+	ctx = strc.WithSpanID(ctx, "0000000.IvQORsV")
+	logger.With("k1", "v1").WithGroup("g1").With("k2", "v2").WarnContext(ctx, "message", "k3", 3)
+	logger.Debug("no trace")
+
+	if err := h.CloseWithTimeout(time.Second); err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 2 {
+		t.Fatalf("expected 2 records, got %d", len(records))
+	}
+
+	r := <-records
+	want := map[string]any{
+		"severityNumber": float64(13),
+		"severityText":   "WARN",
+		"traceId":        "0002111a032c0c2401080c02021c3422",
+		"spanId":         strc.SpanID("0000000.IvQORsV").W3C(),
+	}
+	for k, v := range want {
+		if r[k] != v {
+			t.Errorf("record field %s = %v, want %v", k, r[k], v)
+		}
+	}
+	if r["body"].(map[string]any)["stringValue"] != "message" {
+		t.Errorf("unexpected body %v", r["body"])
+	}
+
+	// k1, build_id and g1 group with k2 and k3 (trace_id is mapped to traceId)
+	attrs := r["attributes"].([]any)
+	keys := make(map[string]any)
+	for _, a := range attrs {
+		keys[a.(map[string]any)["key"].(string)] = a.(map[string]any)["value"]
+	}
+	if _, ok := keys[strc.TraceIDFieldKey]; ok || len(keys) != 3 {
+		t.Errorf("unexpected attributes %v", attrs)
+	}
+	g1 := keys["g1"].(map[string]any)["kvlistValue"].(map[string]any)["values"].([]any)
+	if len(g1) != 2 {
+		t.Errorf("unexpected group %v", g1)
+	}
+
+	r = <-records
+	if r["severityNumber"] != float64(5) || r["traceId"] != nil {
+		t.Errorf("unexpected record %v", r)
+	}
+
+	stats := h.Statistics()
+	if stats.RecordCount != 2 || stats.BatchCount != 1 {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+
+	if err := h.Handle(context.Background(), slog.NewRecord(time.Now(), slog.LevelInfo, "closed", 0)); !errors.Is(err, ErrFullOrClosed) {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+func TestLogHandlerTraceIDFieldKey(t *testing.T) {
+	records := make(chan map[string]any, 10)
+	srv := logCollector(t, records)
+	defer srv.Close()
+
+	h := NewLogHandler(context.Background(), LogHandlerConfig{
+		URL:                srv.URL,
+		DisableCompression: true,
+		SendFrequency:      time.Hour,
+		TraceIDFieldKey:    "tid",
+	})
+	logger := slog.New(strc.NewMultiHandlerOptions(strc.TracerOptions{TraceIDFieldKey: "tid"}, nil, nil, h))
+
+	logger.InfoContext(strc.WithTraceID(context.Background(), "bqzcRlJahlbbBZH"), "message")
+	if err := h.CloseWithTimeout(time.Second); err != nil {
+		t.Fatal(err)
+	}
+
+	r := <-records
+	if r["traceId"] != "0002111a032c0c2401080c02021c3422" {
+		t.Errorf("unexpected trace ID %v", r["traceId"])
+	}
+	for _, a := range r["attributes"].([]any) {
+		if a.(map[string]any)["key"] == "tid" {
+			t.Errorf("trace ID field was not mapped %v", r["attributes"])
+		}
+	}
+}
+
+func TestLogHandlerFlush(t *testing.T) {
+	records := make(chan map[string]any, 10)
+	srv := logCollector(t, records)
+	defer srv.Close()
+
+	h := NewLogHandler(context.Background(), LogHandlerConfig{URL: srv.URL, DisableCompression: true, SendFrequency: time.Hour})
+	defer h.Close()
+
+	slog.New(h).Info("flushed")
+	h.Flush()
+
+	select {
+	case r := <-records:
+		if r["body"].(map[string]any)["stringValue"] != "flushed" {
+			t.Errorf("unexpected record %v", r)
+		}
+	case <-time.After(5 * time.Second):
+		t.Error("record was not flushed")
+	}
+}
+
+func TestSeverityNumber(t *testing.T) {
+	tests := map[slog.Level]int{
+		slog.LevelDebug - 10: 1,
+		slog.LevelDebug:      5,
+		slog.LevelInfo:       9,
+		slog.LevelInfo + 1:   10,
+		slog.LevelWarn:       13,
+		slog.LevelError:      17,
+		slog.LevelError + 20: 24,
+	}
+
+	for level, want := range tests {
+		if got := severityNumber(level); got != want {
+			t.Errorf("severityNumber(%s) = %d, want %d", level, got, want)
+		}
+	}
+}
+
+func TestMergeAttr(t *testing.T) {
+	var attrs []slog.Attr
+	attrs = mergeAttr(attrs, nestAttr([]string{"a", "b"}, slog.String("k1", "v1")))
+	attrs = mergeAttr(attrs, nestAttr([]string{"a"}, slog.String("k2", "v2")))
+	attrs = mergeAttr(attrs, nestAttr([]string{"a", "b"}, slog.String("k1", "v3")))
+
+	want := slog.Group("a", slog.Group("b", slog.String("k1", "v3")), slog.String("k2", "v2"))
+	if len(attrs) != 1 || !attrs[0].Equal(want) {
+		t.Errorf("unexpected attributes %v", attrs)
+	}
+}
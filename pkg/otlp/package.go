@@ -0,0 +1,2 @@
+// OpenTelemetry protocol (OTLP/HTTP JSON) exporters for strc spans and log/slog records.
+package otlp
@@ -0,0 +1,196 @@
+package otlp
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/osbuild/logging"
+	"github.com/osbuild/logging/pkg/strc"
+)
+
+var _ strc.SpanExporter = (*TraceExporter)(nil)
+
+// TraceExporterConfig is the configuration for TraceExporter.
+type TraceExporterConfig struct {
+	// URL is the OTLP/HTTP traces endpoint, for example http://localhost:4318/v1/traces.
+	URL string
+
+	// Headers are additional HTTP headers, for example authorization.
+	Headers map[string]string
+
+	// ServiceName is the service.name resource attribute.
+	ServiceName string
+
+	// ResourceAttributes are additional resource attributes.
+	ResourceAttributes []slog.Attr
+
+	// DisableCompression turns off gzip compression of requests.
+	DisableCompression bool
+}
+
+// TraceExporter is a strc.SpanExporter which sends spans to an OpenTelemetry collector via
+// OTLP/HTTP with JSON encoding. Failed requests are retried. Use it with strc.BatchSpanProcessor
+// for batching.
+type TraceExporter struct {
+	client   *client
+	resource resource
+}
+
+// NewTraceExporter creates a new OTLP trace exporter. Resource attributes include service.name
+// and service.version set to logging.BuildID.
+func NewTraceExporter(config TraceExporterConfig) *TraceExporter {
+	return &TraceExporter{
+		client:   newClient(config.URL, config.Headers, !config.DisableCompression),
+		resource: newResource(config.ServiceName, config.ResourceAttributes),
+	}
+}
+
+func newResource(serviceName string, attrs []slog.Attr) resource {
+	ra := make([]slog.Attr, 0, len(attrs)+2)
+	if serviceName != "" {
+		ra = append(ra, slog.String("service.name", serviceName))
+	}
+	ra = append(ra, slog.String("service.version", logging.BuildID()))
+	ra = append(ra, attrs...)
+
+	return resource{Attributes: convertAttrs(strc.UniqAttrs(ra))}
+}
+
+type exportTraceServiceRequest struct {
+	ResourceSpans []resourceSpans `json:"resourceSpans"`
+}
+
+type resourceSpans struct {
+	Resource   resource     `json:"resource"`
+	ScopeSpans []scopeSpans `json:"scopeSpans"`
+}
+
+type scopeSpans struct {
+	Scope scope  `json:"scope"`
+	Spans []span `json:"spans"`
+}
+
+type span struct {
+	TraceID           string     `json:"traceId"`
+	SpanID            string     `json:"spanId"`
+	ParentSpanID      string     `json:"parentSpanId,omitempty"`
+	Flags             uint32     `json:"flags,omitempty"`
+	Name              string     `json:"name"`
+	Kind              int        `json:"kind"`
+	StartTimeUnixNano string     `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string     `json:"endTimeUnixNano"`
+	Attributes        []keyValue `json:"attributes,omitempty"`
+	Events            []event    `json:"events,omitempty"`
+	Links             []link     `json:"links,omitempty"`
+	Status            status     `json:"status"`
+}
+
+type event struct {
+	TimeUnixNano string     `json:"timeUnixNano"`
+	Name         string     `json:"name"`
+	Attributes   []keyValue `json:"attributes,omitempty"`
+}
+
+type link struct {
+	TraceID    string `json:"traceId"`
+	SpanID     string `json:"spanId"`
+	TraceState string `json:"traceState,omitempty"`
+}
+
+type status struct {
+	Code    int    `json:"code,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+// ExportSpans sends the spans to the collector. Spans with IDs which cannot be converted to
+// W3C format are skipped.
+func (e *TraceExporter) ExportSpans(ctx context.Context, spans []strc.SpanData) error {
+	converted := make([]span, 0, len(spans))
+	for i := range spans {
+		if s, ok := convertSpan(&spans[i]); ok {
+			converted = append(converted, s)
+		}
+	}
+	if len(converted) == 0 {
+		return nil
+	}
+
+	return e.client.send(ctx, exportTraceServiceRequest{
+		ResourceSpans: []resourceSpans{{
+			Resource: e.resource,
+			ScopeSpans: []scopeSpans{{
+				Scope: scope{Name: ScopeName},
+				Spans: converted,
+			}},
+		}},
+	})
+}
+
+// Shutdown does nothing, there are no resources to release.
+func (e *TraceExporter) Shutdown(context.Context) error {
+	return nil
+}
+
+func convertSpan(d *strc.SpanData) (span, bool) {
+	tid, sid := d.TraceID.W3C(), d.SpanID.W3C()
+	if tid == "" || sid == "" {
+		return span{}, false
+	}
+
+	s := span{
+		TraceID:           tid,
+		SpanID:            sid,
+		ParentSpanID:      strc.SpanID(strc.EmptySpanID.ID() + "." + d.SpanID.ParentID()).W3C(),
+		Flags:             uint32(d.Flags),
+		Name:              d.Name,
+		Kind:              convertKind(d.Kind),
+		StartTimeUnixNano: unixNano(d.Start),
+		EndTimeUnixNano:   unixNano(d.End),
+		Attributes:        convertAttrs(d.Attributes),
+		Status:            convertStatus(d.Status, d.StatusDescription),
+	}
+
+	for _, ev := range d.Events {
+		s.Events = append(s.Events, event{
+			TimeUnixNano: unixNano(ev.Time),
+			Name:         ev.Name,
+			Attributes:   convertAttrs(ev.Attributes),
+		})
+	}
+
+	for _, l := range d.Links {
+		if lt, ls := l.TraceID.W3C(), l.SpanID.W3C(); lt != "" && ls != "" {
+			s.Links = append(s.Links, link{TraceID: lt, SpanID: ls, TraceState: l.State})
+		}
+	}
+
+	return s, true
+}
+
+// convertKind converts span kind to OTLP SpanKind enum value
+func convertKind(k strc.SpanKind) int {
+	switch k {
+	case strc.SpanKindServer:
+		return 2
+	case strc.SpanKindClient:
+		return 3
+	case strc.SpanKindProducer:
+		return 4
+	case strc.SpanKindConsumer:
+		return 5
+	default:
+		return 1
+	}
+}
+
+// convertStatus converts status to OTLP StatusCode enum value, the message is only allowed for errors
+func convertStatus(code strc.StatusCode, description string) status {
+	switch code {
+	case strc.StatusOK:
+		return status{Code: 1}
+	case strc.StatusError:
+		return status{Code: 2, Message: description}
+	default:
+		return status{}
+	}
+}
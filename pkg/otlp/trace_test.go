@@ -0,0 +1,171 @@
+package otlp
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/osbuild/logging/pkg/strc"
+)
+
+func collector(t *testing.T, requests chan<- map[string]any) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("unexpected content type %s", r.Header.Get("Content-Type"))
+		}
+		if r.Header.Get("Authorization") != "Bearer token" {
+			t.Errorf("unexpected authorization %s", r.Header.Get("Authorization"))
+		}
+		if r.Header.Get("Content-Encoding") != "gzip" {
+			t.Errorf("unexpected content encoding %s", r.Header.Get("Content-Encoding"))
+		}
+
+		zr, err := gzip.NewReader(r.Body)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+
+		m := map[string]any{}
+		if err := json.NewDecoder(zr).Decode(&m); err != nil {
+			t.Error(err)
+		}
+		requests <- m
+	}))
+}
+
+func TestTraceExporter(t *testing.T) {
+	requests := make(chan map[string]any, 1)
+	srv := collector(t, requests)
+	defer srv.Close()
+
+	exporter := NewTraceExporter(TraceExporterConfig{
+		URL:                srv.URL,
+		Headers:            map[string]string{"Authorization": "Bearer token"},
+		ServiceName:        "test",
+		ResourceAttributes: []slog.Attr{slog.String("deployment.environment", "stage")},
+	})
+
+	started := time.Unix(1700000000, 0)
+	err := exporter.ExportSpans(context.Background(), []strc.SpanData{
+		{
+			TraceID:    "bqzcRlJahlbbBZH",
+			SpanID:     "IvQORsV.kYcTpgn",
+			Flags:      strc.FlagSampled,
+			Name:       "child",
+			Kind:       strc.SpanKindClient,
+			Start:      started,
+			End:        started.Add(time.Second),
+			Attributes: []slog.Attr{slog.Int("count", 42), slog.Group("g", slog.Bool("b", true))},
+			Events: []strc.EventData{
+				{Name: "event", Time: started.Add(time.Millisecond), Attributes: []slog.Attr{slog.String("k", "v")}},
+			},
+			Links:             []strc.SpanContext{{TraceID: "4bf92f3577b34da6a3ce929d0e0e4736", SpanID: "0000000.00f067aa0ba902b7"}},
+			Status:            strc.StatusError,
+			StatusDescription: "failed",
+		},
+		{
+			TraceID: strc.EmptyTraceID,
+			SpanID:  strc.EmptySpanID,
+			Name:    "invalid",
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := <-requests
+	rs := got["resourceSpans"].([]any)[0].(map[string]any)
+
+	resourceAttrs := rs["resource"].(map[string]any)["attributes"].([]any)
+	if len(resourceAttrs) != 3 || resourceAttrs[0].(map[string]any)["key"] != "service.name" {
+		t.Errorf("unexpected resource %v", rs["resource"])
+	}
+
+	spans := rs["scopeSpans"].([]any)[0].(map[string]any)["spans"].([]any)
+	if len(spans) != 1 {
+		t.Fatalf("expected one span, got %v", spans)
+	}
+
+	s := spans[0].(map[string]any)
+	want := map[string]any{
+		"traceId":           "0002111a032c0c2401080c02021c3422",
+		"spanId":            strc.SpanID("0000000.kYcTpgn").W3C(),
+		"parentSpanId":      strc.SpanID("0000000.IvQORsV").W3C(),
+		"name":              "child",
+		"kind":              float64(3),
+		"flags":             float64(1),
+		"startTimeUnixNano": "1700000000000000000",
+		"endTimeUnixNano":   "1700000001000000000",
+	}
+	for k, v := range want {
+		if s[k] != v {
+			t.Errorf("span field %s = %v, want %v", k, s[k], v)
+		}
+	}
+
+	attrs := s["attributes"].([]any)
+	if attrs[0].(map[string]any)["value"].(map[string]any)["intValue"] != "42" {
+		t.Errorf("unexpected attributes %v", attrs)
+	}
+	if _, ok := attrs[1].(map[string]any)["value"].(map[string]any)["kvlistValue"]; !ok {
+		t.Errorf("unexpected group attribute %v", attrs)
+	}
+
+	if ev := s["events"].([]any)[0].(map[string]any); ev["name"] != "event" || ev["timeUnixNano"] != "1700000000001000000" {
+		t.Errorf("unexpected event %v", ev)
+	}
+	if l := s["links"].([]any)[0].(map[string]any); l["traceId"] != "4bf92f3577b34da6a3ce929d0e0e4736" || l["spanId"] != "00f067aa0ba902b7" {
+		t.Errorf("unexpected link %v", l)
+	}
+	if st := s["status"].(map[string]any); st["code"] != float64(2) || st["message"] != "failed" {
+		t.Errorf("unexpected status %v", st)
+	}
+}
+
+func TestTraceExporterRootSpan(t *testing.T) {
+	d := strc.SpanData{TraceID: "bqzcRlJahlbbBZH", SpanID: "0000000.IvQORsV"}
+	s, ok := convertSpan(&d)
+	if !ok || s.ParentSpanID != "" || s.Kind != 1 || s.Status.Code != 0 {
+		t.Errorf("unexpected root span %+v", s)
+	}
+}
+
+func TestTraceExporterRetry(t *testing.T) {
+	var counter atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if counter.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer srv.Close()
+
+	exporter := NewTraceExporter(TraceExporterConfig{URL: srv.URL, DisableCompression: true})
+	err := exporter.ExportSpans(context.Background(), []strc.SpanData{{TraceID: "bqzcRlJahlbbBZH", SpanID: "0000000.IvQORsV"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if counter.Load() != 2 {
+		t.Errorf("expected a retry, got %d requests", counter.Load())
+	}
+}
+
+func TestTraceExporterBadRequest(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer srv.Close()
+
+	exporter := NewTraceExporter(TraceExporterConfig{URL: srv.URL})
+	err := exporter.ExportSpans(context.Background(), []strc.SpanData{{TraceID: "bqzcRlJahlbbBZH", SpanID: "0000000.IvQORsV"}})
+	if !errors.Is(err, ErrResponseNotOK) {
+		t.Errorf("unexpected error %v", err)
+	}
+}
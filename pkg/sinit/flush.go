@@ -10,6 +10,7 @@ import (
 	"github.com/getsentry/sentry-go"
 	"github.com/lzap/cloudwatchwriter2"
 	"github.com/osbuild/logging/pkg/logrus"
+	"github.com/osbuild/logging/pkg/otlp"
 	"github.com/osbuild/logging/pkg/splunk"
 	"github.com/osbuild/logging/pkg/strc"
 )
@@ -18,12 +19,18 @@ import (
 // logging configuration, it issues flush commands to various systems which
 // behave differently:
 //
-// CloudWatch and Splunk handlers issue a flush command that has no guarantee of
-// completion, meaning logs may not be flushed immediately. No blocking is
-// performed.
+// CloudWatch, Splunk and OTLP logs handlers issue a flush command that has no
+// guarantee of completion, meaning logs may not be flushed immediately. No
+// blocking is performed.
 //
 // Sentry SDK flushes logs with blocking up to 2 seconds.
 //
+// Span processor exports all queued spans with blocking until the export
+// finishes.
+//
+// Tail sampling handlers evaluate all held traces, including unfinished ones,
+// and forward the matching ones to outputs before they are flushed.
+//
 // Calling Flush without previously calling InitializeLogging will return
 // ErrNotInitialized.
 //
@@ -37,6 +44,15 @@ func Flush() error {
 		return ErrNotInitialized
 	}
 
+	// tail sampling handlers forward held traces into outputs, do it first
+	for _, h := range res.handlersTail {
+		_ = h.Flush()
+	}
+
+	for _, p := range res.spanProcessors {
+		_ = p.Flush()
+	}
+
 	if res.handlerSplunk != nil {
 		res.handlerSplunk.Flush()
 	}
@@ -45,6 +61,10 @@ func Flush() error {
 		res.handlerCloudWatch.Flush()
 	}
 
+	if res.handlerOTLPLog != nil {
+		res.handlerOTLPLog.Flush()
+	}
+
 	sentry.Flush(2 * time.Second)
 
 	return nil
@@ -72,9 +92,17 @@ func Close(timeout time.Duration) error {
 		return ErrNotInitialized
 	}
 
-	errs := make(chan error, 3)
+	// tail sampling handlers forward held traces into outputs, do it first
+	var tailErrs []error
+	for _, h := range res.handlersTail {
+		if err := h.Flush(); err != nil {
+			tailErrs = append(tailErrs, err)
+		}
+	}
+
+	errs := make(chan error, 5)
 	wg := sync.WaitGroup{}
-	wg.Add(3)
+	wg.Add(5)
 
 	go func() {
 		defer wg.Done()
@@ -104,6 +132,38 @@ func Close(timeout time.Duration) error {
 		}
 	}()
 
+	go func() {
+		defer wg.Done()
+
+		if res.handlerOTLPLog != nil {
+			if err := res.handlerOTLPLog.CloseWithTimeout(timeout); err != nil {
+				if errors.Is(err, otlp.ErrCloseTimeout) {
+					errs <- fmt.Errorf("%w: %w", ErrTimeoutDuringClose, err)
+				} else {
+					errs <- err
+				}
+			}
+		}
+	}()
+
+	go func() {
+		defer wg.Done()
+
+		var spanErrs []error
+		for _, p := range res.spanProcessors {
+			if err := p.CloseWithTimeout(timeout); err != nil {
+				if errors.Is(err, strc.ErrCloseTimeout) {
+					spanErrs = append(spanErrs, fmt.Errorf("%w: %w", ErrTimeoutDuringClose, err))
+				} else {
+					spanErrs = append(spanErrs, err)
+				}
+			}
+		}
+		if err := errors.Join(spanErrs...); err != nil {
+			errs <- err
+		}
+	}()
+
 	go func() {
 		defer wg.Done()
 
@@ -133,7 +193,7 @@ func Close(timeout time.Duration) error {
 	res = nil
 
 	// Collect all errors from the channel as well
-	var result []error
+	result := tailErrs
 	for err := range errs {
 		result = append(result, err)
 	}
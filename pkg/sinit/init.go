@@ -9,12 +9,16 @@ import (
 	"log/slog"
 	"net/url"
 	"os"
+	"path"
+	"regexp"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/getsentry/sentry-go"
 	"github.com/lzap/cloudwatchwriter2"
 	"github.com/osbuild/logging/pkg/logrus"
+	"github.com/osbuild/logging/pkg/otlp"
 	"github.com/osbuild/logging/pkg/splunk"
 	"github.com/osbuild/logging/pkg/strc"
 	slogsentry "github.com/samber/slog-sentry/v2"
@@ -33,9 +37,15 @@ type LoggingConfig struct {
 
 	SentryConfig SentryConfig
 
+	OTLPLogConfig OTLPLogConfig
+
 	TracingConfig TracingConfig
 
+	OTLPTraceConfig OTLPTraceConfig
+
 	LogrusConfig LogrusConfig
+
+	RedactConfig RedactConfig
 }
 
 // StdoutConfig is the configuration for the standard output.
@@ -132,6 +142,144 @@ type TracingConfig struct {
 	// ContextCallback is an optional callback function that is called for each log entry
 	// to add additional attributes to the log entry.
 	ContextCallback strc.MultiCallback
+
+	// TailSamplingConfig is an optional tail-based sampling configuration.
+	TailSamplingConfig TailSamplingConfig
+
+	// Sampler is an optional head-based sampler, for example strc.ParentBased(strc.TraceIDRatioBased(0.1)).
+	// Unsampled spans are not logged. All spans are sampled when nil.
+	Sampler strc.Sampler
+
+	// SpanExportConfig is an optional configuration of typed span export.
+	SpanExportConfig SpanExportConfig
+
+	// SingleRecord emits a single record per span when it ends instead of a start and an end
+	// record. Events are buffered and added to the end record. See strc.WithSingleRecord.
+	SingleRecord bool
+}
+
+// SpanExportConfig is the configuration for exporting finished spans via strc.SpanExporter.
+// Spans are queued and exported in batches by strc.BatchSpanProcessor which is flushed by
+// Flush and Close.
+type SpanExportConfig struct {
+	// Exporter is the span exporter, span export is enabled when set.
+	Exporter strc.SpanExporter
+
+	// MaxQueueSize is the maximum number of spans waiting for export. Default value is
+	// strc.DefaultMaxQueueSize.
+	MaxQueueSize int
+
+	// MaxBatchSize is the maximum number of spans exported at once. Default value is
+	// strc.DefaultMaxBatchSize.
+	MaxBatchSize int
+
+	// FlushInterval is the interval between periodic exports. Default value is
+	// strc.DefaultFlushInterval.
+	FlushInterval time.Duration
+}
+
+// TailSamplingConfig is the configuration for tail-based sampling of spans. Span records are
+// held in memory until the trace ends and sent to outputs only when a rule matches. Regular
+// log records are not affected.
+type TailSamplingConfig struct {
+	// Enabled is a flag to enable tail-based sampling.
+	Enabled bool
+
+	// SlowThreshold keeps traces with at least one span longer than this duration. Zero
+	// disables this rule.
+	SlowThreshold time.Duration
+
+	// KeepErrors keeps traces with at least one error-level record.
+	KeepErrors bool
+
+	// Percentage of the remaining traces which are kept (0-100).
+	Percentage float64
+
+	// MaxRecords is the maximum number of span records held in memory per output. Default
+	// value is strc.DefaultTailMaxRecords.
+	MaxRecords int
+
+	// Timeout is the time after which traces that never finished are evaluated. Default
+	// value is strc.DefaultTailTimeout.
+	Timeout time.Duration
+}
+
+// OTLPLogConfig is the configuration for the OpenTelemetry collector logs output via OTLP/HTTP
+// JSON.
+type OTLPLogConfig struct {
+	// Enabled is a flag to enable this output.
+	Enabled bool
+
+	// Logging level for this output. Strings "debug", "info", "warn", "error", "fatal", "panic" are accepted.
+	// Keep in mind that log/slog has only 4 levels: Debug, Info, Warn, Error. Default value is "debug".
+	Level string
+
+	// URL is the OTLP/HTTP logs endpoint, for example http://localhost:4318/v1/logs.
+	URL string
+
+	// Headers are additional HTTP headers, for example authorization.
+	Headers map[string]string
+
+	// ServiceName is the service.name resource attribute.
+	ServiceName string
+
+	// ResourceAttributes are additional resource attributes.
+	ResourceAttributes []slog.Attr
+}
+
+// OTLPTraceConfig is the configuration for exporting spans to an OpenTelemetry collector via
+// OTLP/HTTP JSON. Tracing must be enabled via TracingConfig, otherwise InitializeLogging returns
+// ErrTracingDisabled. Spans are exported in batches, see SpanExportConfig for batching defaults.
+type OTLPTraceConfig struct {
+	// Enabled is a flag to enable this output.
+	Enabled bool
+
+	// URL is the OTLP/HTTP traces endpoint, for example http://localhost:4318/v1/traces.
+	URL string
+
+	// Headers are additional HTTP headers, for example authorization.
+	Headers map[string]string
+
+	// ServiceName is the service.name resource attribute.
+	ServiceName string
+
+	// ResourceAttributes are additional resource attributes.
+	ResourceAttributes []slog.Attr
+
+	// FlushInterval is the interval between periodic exports. Default value is
+	// strc.DefaultFlushInterval.
+	FlushInterval time.Duration
+}
+
+// RedactConfig is the configuration for redaction of sensitive attributes, it applies to all
+// outputs. See strc.RedactHandler.
+type RedactConfig struct {
+	// Enabled is a flag to enable redaction.
+	Enabled bool
+
+	// Rules are evaluated in order, the first rule matching the key wins.
+	Rules []RedactRule
+
+	// Mask is the replacement for the "mask" action. Default value is strc.DefaultRedactMask.
+	Mask string
+
+	// HMACKey is the secret key for the "hmac" action. It must be the same for all instances
+	// and restarts, otherwise pseudonyms do not correlate.
+	HMACKey string
+}
+
+// RedactRule matches attributes by key or value, see strc.RedactRule.
+type RedactRule struct {
+	// Keys are attribute key names or glob patterns matched against the key and the full path
+	// with groups separated by dots, for example "password", "*token*" or "request.headers.*".
+	Keys []string
+
+	// Values are regular expressions matched against string values.
+	Values []string
+
+	// Action is the action for matched attributes. Possible values are "mask", "drop" and
+	// "hmac". Default value is "mask".
+	Action string
 }
 
 // LogrusConfig is the configuration for the logrus proxy.
@@ -149,6 +297,9 @@ type resources struct {
 	handlerMulti      *strc.MultiHandler
 	handlerSplunk     *splunk.SplunkHandler
 	handlerCloudWatch *cloudwatchwriter2.Handler
+	handlerOTLPLog    *otlp.LogHandler
+	handlersTail      []*strc.TailSamplingHandler
+	spanProcessors    []*strc.BatchSpanProcessor
 	sentryEnabled     bool
 	prevSlogger       *slog.Logger
 }
@@ -161,6 +312,8 @@ var (
 	ErrInvalidURL           = errors.New("invalid URL")
 	ErrMissingURL           = errors.New("missing URL")
 	ErrSentryInitialization = errors.New("sentry initialization error")
+	ErrInvalidRedactRule    = errors.New("invalid redact rule")
+	ErrTracingDisabled      = errors.New("tracing is not enabled")
 )
 
 var osHostname = os.Hostname
@@ -273,6 +426,47 @@ func InitializeLogging(ctx context.Context, config LoggingConfig) error {
 		handlers = append(handlers, h)
 	}
 
+	if config.OTLPLogConfig.Enabled {
+		res.handlerOTLPLog = otlp.NewLogHandler(ctx, otlp.LogHandlerConfig{
+			Level:              parseLevel(config.OTLPLogConfig.Level),
+			URL:                config.OTLPLogConfig.URL,
+			Headers:            config.OTLPLogConfig.Headers,
+			ServiceName:        config.OTLPLogConfig.ServiceName,
+			ResourceAttributes: config.OTLPLogConfig.ResourceAttributes,
+		})
+		handlers = append(handlers, res.handlerOTLPLog)
+	}
+
+	if config.RedactConfig.Enabled {
+		rc := strc.RedactConfig{
+			Mask:    config.RedactConfig.Mask,
+			HMACKey: []byte(config.RedactConfig.HMACKey),
+		}
+		for _, r := range config.RedactConfig.Rules {
+			// rules were validated
+			rule, _ := parseRedactRule(r)
+			rc.Rules = append(rc.Rules, rule)
+		}
+		for i := range handlers {
+			handlers[i] = strc.NewRedactHandler(handlers[i], rc)
+		}
+	}
+
+	if config.TracingConfig.TailSamplingConfig.Enabled {
+		tc := config.TracingConfig.TailSamplingConfig
+		for i := range handlers {
+			h := strc.NewTailSamplingHandler(handlers[i], strc.TailSamplingConfig{
+				SlowThreshold: tc.SlowThreshold,
+				KeepErrors:    tc.KeepErrors,
+				Percentage:    tc.Percentage,
+				MaxRecords:    tc.MaxRecords,
+				Timeout:       tc.Timeout,
+			})
+			res.handlersTail = append(res.handlersTail, h)
+			handlers[i] = h
+		}
+	}
+
 	// create the combined handler
 	res.handlerMulti = strc.NewMultiHandlerCustom(
 		config.TracingConfig.CustomAttrs,
@@ -287,7 +481,36 @@ func InitializeLogging(ctx context.Context, config LoggingConfig) error {
 
 	// configure tracing
 	if config.TracingConfig.Enabled {
-		strc.SetLogger(logger)
+		var opts []strc.TracerOption
+		if config.TracingConfig.Sampler != nil {
+			opts = append(opts, strc.WithSampler(config.TracingConfig.Sampler))
+		}
+		if config.TracingConfig.SingleRecord {
+			opts = append(opts, strc.WithSingleRecord())
+		}
+		if ec := config.TracingConfig.SpanExportConfig; ec.Exporter != nil {
+			p := strc.NewBatchSpanProcessor(ec.Exporter, strc.BatchSpanProcessorConfig{
+				MaxQueueSize:  ec.MaxQueueSize,
+				MaxBatchSize:  ec.MaxBatchSize,
+				FlushInterval: ec.FlushInterval,
+			})
+			res.spanProcessors = append(res.spanProcessors, p)
+			opts = append(opts, strc.WithSpanProcessor(p))
+		}
+		if oc := config.OTLPTraceConfig; oc.Enabled {
+			e := otlp.NewTraceExporter(otlp.TraceExporterConfig{
+				URL:                oc.URL,
+				Headers:            oc.Headers,
+				ServiceName:        oc.ServiceName,
+				ResourceAttributes: oc.ResourceAttributes,
+			})
+			p := strc.NewBatchSpanProcessor(e, strc.BatchSpanProcessorConfig{
+				FlushInterval: oc.FlushInterval,
+			})
+			res.spanProcessors = append(res.spanProcessors, p)
+			opts = append(opts, strc.WithSpanProcessor(p))
+		}
+		strc.SetLogger(logger, opts...)
 	}
 
 	// configure logrus proxy
@@ -330,9 +553,78 @@ func validate(config LoggingConfig) error {
 		}
 	}
 
+	if config.OTLPLogConfig.Enabled {
+		if config.OTLPLogConfig.URL == "" {
+			return fmt.Errorf("%w: OTLP log URL is required", ErrMissingURL)
+		}
+
+		_, err := url.Parse(config.OTLPLogConfig.URL)
+		if err != nil {
+			return fmt.Errorf("%w: '%s': %w", ErrInvalidURL, config.OTLPLogConfig.URL, err)
+		}
+	}
+
+	if config.OTLPTraceConfig.Enabled {
+		if config.OTLPTraceConfig.URL == "" {
+			return fmt.Errorf("%w: OTLP trace URL is required", ErrMissingURL)
+		}
+
+		_, err := url.Parse(config.OTLPTraceConfig.URL)
+		if err != nil {
+			return fmt.Errorf("%w: '%s': %w", ErrInvalidURL, config.OTLPTraceConfig.URL, err)
+		}
+
+		if !config.TracingConfig.Enabled {
+			return fmt.Errorf("%w: OTLP trace export requires tracing", ErrTracingDisabled)
+		}
+	}
+
+	if config.RedactConfig.Enabled {
+		for _, r := range config.RedactConfig.Rules {
+			rule, err := parseRedactRule(r)
+			if err != nil {
+				return err
+			}
+			if rule.Action == strc.RedactHMAC && config.RedactConfig.HMACKey == "" {
+				return fmt.Errorf("%w: HMAC key is required", ErrInvalidRedactRule)
+			}
+		}
+	}
+
 	return nil
 }
 
+func parseRedactRule(r RedactRule) (strc.RedactRule, error) {
+	rule := strc.RedactRule{Keys: r.Keys}
+
+	switch strings.ToLower(r.Action) {
+	case "", "mask":
+		rule.Action = strc.RedactMask
+	case "drop":
+		rule.Action = strc.RedactDrop
+	case "hmac":
+		rule.Action = strc.RedactHMAC
+	default:
+		return rule, fmt.Errorf("%w: unknown action '%s'", ErrInvalidRedactRule, r.Action)
+	}
+
+	for _, k := range r.Keys {
+		if _, err := path.Match(k, ""); err != nil {
+			return rule, fmt.Errorf("%w: '%s': %w", ErrInvalidRedactRule, k, err)
+		}
+	}
+
+	for _, v := range r.Values {
+		re, err := regexp.Compile(v)
+		if err != nil {
+			return rule, fmt.Errorf("%w: '%s': %w", ErrInvalidRedactRule, v, err)
+		}
+		rule.Values = append(rule.Values, re)
+	}
+
+	return rule, nil
+}
+
 func parseLevel(level string) slog.Level {
 	switch strings.ToLower(level) {
 	case "debug", "trace":
@@ -1,6 +1,7 @@
 package sinit
 
 import (
+	"compress/gzip"
 	"context"
 	"errors"
 	"io"
@@ -10,6 +11,8 @@ import (
 	"strings"
 	"testing"
 	"time"
+
+	"github.com/osbuild/logging/pkg/strc"
 )
 
 func init() {
@@ -173,3 +176,237 @@ func TestValidationSplunkWithoutHostname(t *testing.T) {
 		panic("no splunk record in 6s")
 	}
 }
+
+func TestTailSamplingConfiguration(t *testing.T) {
+	ctx := context.Background()
+	cfg := LoggingConfig{
+		StdoutConfig: StdoutConfig{
+			Enabled: true,
+			Level:   "error",
+		},
+		TracingConfig: TracingConfig{
+			Enabled: true,
+			TailSamplingConfig: TailSamplingConfig{
+				Enabled:    true,
+				KeepErrors: true,
+			},
+		},
+	}
+
+	err := InitializeLogging(ctx, cfg)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if len(res.handlersTail) != 1 {
+		t.Fatalf("expected one tail sampling handler, got %d", len(res.handlersTail))
+	}
+
+	err = Close(100 * time.Millisecond)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+}
+
+func TestSpanExportConfiguration(t *testing.T) {
+	ctx := context.Background()
+	exporter := strc.NewInMemoryExporter()
+	cfg := LoggingConfig{
+		TracingConfig: TracingConfig{
+			Enabled: true,
+			SpanExportConfig: SpanExportConfig{
+				Exporter:      exporter,
+				FlushInterval: time.Hour,
+			},
+		},
+	}
+
+	err := InitializeLogging(ctx, cfg)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	span, _ := strc.Start(ctx, "exported")
+	span.End()
+
+	err = Close(time.Second)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if spans := exporter.Spans(); len(spans) != 1 || spans[0].Name != "exported" {
+		t.Errorf("expected one exported span, got %v", spans)
+	}
+}
+
+func TestOTLPTraceConfiguration(t *testing.T) {
+	requests := make(chan struct{}, 10)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/traces" || r.Header.Get("Content-Encoding") != "gzip" {
+			t.Errorf("unexpected request %s %v", r.URL.Path, r.Header)
+		}
+		requests <- struct{}{}
+	}))
+	defer srv.Close()
+
+	ctx := context.Background()
+	cfg := LoggingConfig{
+		TracingConfig: TracingConfig{
+			Enabled: true,
+		},
+		OTLPTraceConfig: OTLPTraceConfig{
+			Enabled:     true,
+			URL:         srv.URL + "/v1/traces",
+			ServiceName: "test",
+		},
+	}
+
+	err := InitializeLogging(ctx, cfg)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	span, _ := strc.Start(ctx, "exported")
+	span.End()
+
+	err = Close(time.Second)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if len(requests) != 1 {
+		t.Errorf("expected one request, got %d", len(requests))
+	}
+}
+
+func TestValidationOTLPTracingDisabled(t *testing.T) {
+	cfg := LoggingConfig{
+		OTLPTraceConfig: OTLPTraceConfig{
+			Enabled: true,
+			URL:     "http://localhost:4318/v1/traces",
+		},
+	}
+
+	err := validate(cfg)
+	if !errors.Is(err, ErrTracingDisabled) {
+		t.Errorf("expected ErrTracingDisabled, got %v", err)
+	}
+}
+
+func TestValidationOTLPEmptyURL(t *testing.T) {
+	cfg := LoggingConfig{
+		OTLPTraceConfig: OTLPTraceConfig{
+			Enabled: true,
+		},
+	}
+
+	err := validate(cfg)
+	if !errors.Is(err, ErrMissingURL) {
+		t.Errorf("expected ErrMissingURL, got %v", err)
+	}
+}
+
+func TestOTLPLogConfiguration(t *testing.T) {
+	requests := make(chan string, 10)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests <- r.URL.Path
+	}))
+	defer srv.Close()
+
+	ctx := context.Background()
+	cfg := LoggingConfig{
+		OTLPLogConfig: OTLPLogConfig{
+			Enabled:     true,
+			Level:       "info",
+			URL:         srv.URL + "/v1/logs",
+			ServiceName: "test",
+		},
+	}
+
+	err := InitializeLogging(ctx, cfg)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	slog.InfoContext(ctx, "message")
+
+	err = Close(time.Second)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if len(requests) != 1 || <-requests != "/v1/logs" {
+		t.Errorf("expected one logs request")
+	}
+}
+
+func TestValidationRedactRules(t *testing.T) {
+	for _, rule := range []RedactRule{
+		{Action: "unknown"},
+		{Keys: []string{"[a-"}},
+		{Values: []string{"(unclosed"}},
+		{Keys: []string{"email"}, Action: "hmac"},
+	} {
+		cfg := LoggingConfig{
+			RedactConfig: RedactConfig{
+				Enabled: true,
+				Rules:   []RedactRule{rule},
+			},
+		}
+
+		if err := validate(cfg); !errors.Is(err, ErrInvalidRedactRule) {
+			t.Errorf("expected ErrInvalidRedactRule for %v, got %v", rule, err)
+		}
+	}
+}
+
+func TestRedactConfiguration(t *testing.T) {
+	bodies := make(chan string, 10)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		reader, err := gzip.NewReader(r.Body)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		body, _ := io.ReadAll(reader)
+		bodies <- string(body)
+	}))
+	defer srv.Close()
+
+	ctx := context.Background()
+	cfg := LoggingConfig{
+		OTLPLogConfig: OTLPLogConfig{
+			Enabled: true,
+			Level:   "info",
+			URL:     srv.URL + "/v1/logs",
+		},
+		RedactConfig: RedactConfig{
+			Enabled: true,
+			Rules: []RedactRule{
+				{Keys: []string{"password"}, Action: "drop"},
+				{Values: []string{`[a-z]+@example\.com`}, Action: "hmac"},
+			},
+			HMACKey: "key",
+		},
+	}
+
+	err := InitializeLogging(ctx, cfg)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	slog.InfoContext(ctx, "message", "password", "secret", "email", "joe@example.com")
+
+	err = Close(time.Second)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if len(bodies) != 1 {
+		t.Fatalf("expected one logs request")
+	}
+	body := <-bodies
+	if strings.Contains(body, "secret") || strings.Contains(body, "joe") || !strings.Contains(body, "hmac:") {
+		t.Errorf("attributes were not redacted: %s", body)
+	}
+}
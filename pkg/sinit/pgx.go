@@ -30,7 +30,7 @@ type dbTracer struct {
 var _ pgx.QueryTracer = &dbTracer{}
 
 func (dt *dbTracer) TraceQueryStart(ctx context.Context, conn *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
-	if !dt.logger.Enabled(ctx, slog.LevelDebug) {
+	if !dt.logger.Enabled(ctx, slog.LevelDebug) || !strc.IsSampled(ctx) {
 		return ctx
 	}
 
@@ -42,6 +42,7 @@ func (dt *dbTracer) TraceQueryStart(ctx context.Context, conn *pgx.Conn, data pg
 
 	tracer := strc.NewTracer(dt.logger)
 	span, ctx := tracer.Start(ctx, "query",
+		strc.WithKind(strc.SpanKindClient),
 		"conn_id", pid,
 		"sql", sql,
 		"args", args,
@@ -58,11 +59,7 @@ func (dt *dbTracer) TraceQueryEnd(ctx context.Context, conn *pgx.Conn, data pgx.
 		return
 	}
 
-	if data.Err != nil {
-		span.End("err", data.Err, "ct", data.CommandTag.String())
-	} else {
-		span.End("ct", data.CommandTag.String())
-	}
+	span.EndWithError(data.Err, "ct", data.CommandTag.String())
 }
 
 const maxSqlLogLength = 100
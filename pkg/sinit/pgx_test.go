@@ -12,6 +12,7 @@ import (
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/osbuild/logging/pkg/collect"
+	"github.com/osbuild/logging/pkg/strc"
 )
 
 func TestFormatSqlSimple(t *testing.T) {
@@ -125,6 +126,7 @@ func TestTraceQuery(t *testing.T) {
 				"msg": "span query started",
 				"span": map[string]any{
 					"name":    "query",
+					"kind":    "client",
 					"sql":     "select $1 $2",
 					"args":    "[hello 13]",
 					"conn_id": uint64(0),
@@ -139,6 +141,7 @@ func TestTraceQuery(t *testing.T) {
 				"msg": "span query finished",
 				"span": map[string]any{
 					"name":    "query",
+					"kind":    "client",
 					"sql":     "select $1 $2",
 					"args":    "[hello 13]",
 					"conn_id": uint64(0),
@@ -167,3 +170,19 @@ func TestTraceQuery(t *testing.T) {
 		}
 	}
 }
+
+func TestTraceQueryNotSampled(t *testing.T) {
+	ctx := strc.WithTraceFlags(context.Background(), 0)
+	ch := collect.NewTestHandler(slog.LevelDebug, false, false, false)
+
+	dt := dbTracer{
+		logger: slog.New(ch),
+	}
+
+	ctx = dt.TraceQueryStart(ctx, nil, pgx.TraceQueryStartData{SQL: "select 1"})
+	dt.TraceQueryEnd(ctx, nil, pgx.TraceQueryEndData{CommandTag: pgconn.NewCommandTag("SELECT")})
+
+	if ch.Count() != 0 {
+		t.Errorf("Expected no records for unsampled trace, got: %v", ch.All())
+	}
+}
@@ -0,0 +1,84 @@
+package strc
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// WithBudget sets a time budget of the span, for example an SLA of a build step. When the span
+// is still open after the budget elapses, a warning-level "over_budget" event is logged
+// immediately (also in the single record mode), so stuck spans are visible before they end.
+//
+// The end record of a span over the budget has "over_budget" attribute set to true. When the
+// span context was cancelled or hit a deadline by the time the span ends, the cause is added as
+// "cancel_cause" attribute, also for spans within the budget. The budget does not cancel the
+// context, use context.WithTimeout for that.
+//
+//	span, ctx := strc.Start(ctx, "build", strc.WithBudget(30*time.Minute))
+//	defer span.End()
+func WithBudget(d time.Duration) StartOption {
+	return func(c *startConfig) {
+		c.budget = d
+	}
+}
+
+// spanBudget holds the budget state of a span, fields are protected by mu which is held while
+// the over budget event is logged, so the event is never logged after the end record.
+type spanBudget struct {
+	mu     sync.Mutex
+	budget time.Duration
+	timer  *time.Timer
+	over   bool
+	ended  bool
+}
+
+// startBudget starts the budget timer, it fires immediately when the start time is in the past
+// and the budget is already exceeded. The timer uses the wall clock.
+func (s *Span) startBudget(d time.Duration) {
+	delay := d
+	if s.tracer.clock == nil {
+		delay -= time.Since(s.started)
+	}
+
+	s.budget = &spanBudget{budget: d}
+	s.budget.timer = time.AfterFunc(delay, s.overBudget)
+}
+
+// overBudget is called by the budget timer while the span is still open.
+func (s *Span) overBudget() {
+	s.budget.mu.Lock()
+	defer s.budget.mu.Unlock()
+
+	if s.budget.ended {
+		return
+	}
+	s.budget.over = true
+
+	at := s.tracer.now()
+	args := []any{slog.Duration("budget", s.budget.budget)}
+	s.recordEvent("over_budget", at, args)
+	s.logEvent(max(s.tracer.opts.level(), slog.LevelWarn), 0, "over_budget", at, args)
+}
+
+// endBudget stops the budget timer and sets budget attributes of the span.
+func (s *Span) endBudget(finished time.Time) {
+	s.budget.timer.Stop()
+
+	s.budget.mu.Lock()
+	s.budget.ended = true
+	over := s.budget.over || finished.Sub(s.started) > s.budget.budget
+	s.budget.mu.Unlock()
+
+	var attrs []slog.Attr
+	if over {
+		attrs = append(attrs, slog.Bool("over_budget", true))
+	}
+	if s.ctx.Err() != nil {
+		attrs = append(attrs, slog.String("cancel_cause", context.Cause(s.ctx).Error()))
+	}
+	if len(attrs) > 0 {
+		s.SetAttributes(attrs...)
+	}
+}
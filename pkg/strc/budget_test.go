@@ -0,0 +1,102 @@
+package strc
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/osbuild/logging/pkg/collect"
+)
+
+func TestBudget(t *testing.T) {
+	ch := collect.NewTestHandler(slog.LevelDebug, false, false, true)
+	tracer := NewTracer(slog.New(ch))
+
+	ctx, cancel := context.WithCancelCause(context.Background())
+	span, _ := tracer.Start(ctx, "build", WithBudget(10*time.Millisecond))
+
+	deadline := time.Now().Add(5 * time.Second)
+	for !ch.Contains("over_budget", "span", "event") {
+		if time.Now().After(deadline) {
+			t.Fatalf("over budget event not logged: %s", ch.String())
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if !ch.Contains("WARN", slog.LevelKey) {
+		t.Errorf("over budget event is not a warning: %s", ch.String())
+	}
+
+	cancel(errors.New("worker stopped"))
+	span.End()
+
+	if !ch.Contains(true, "span", "over_budget") || !ch.Contains("worker stopped", "span", "cancel_cause") {
+		t.Errorf("unexpected end record: %v", ch.Last())
+	}
+}
+
+func TestBudgetNotExceeded(t *testing.T) {
+	ch := collect.NewTestHandler(slog.LevelDebug, false, false, true)
+	tracer := NewTracer(slog.New(ch))
+
+	span, _ := tracer.Start(context.Background(), "build", WithBudget(time.Hour))
+	span.End()
+
+	if ch.Count() != 2 || ch.CountWith("span", "over_budget") != 0 || ch.CountWith("span", "event") != 0 {
+		t.Errorf("unexpected records: %s", ch.String())
+	}
+}
+
+func TestBudgetCancelled(t *testing.T) {
+	ch := collect.NewTestHandler(slog.LevelDebug, false, false, true)
+	tracer := NewTracer(slog.New(ch))
+
+	ctx, cancel := context.WithCancelCause(context.Background())
+	span, _ := tracer.Start(ctx, "build", WithBudget(time.Hour))
+	cancel(errors.New("worker stopped"))
+	span.End()
+
+	if ch.CountWith("span", "over_budget") != 0 || !ch.Contains("worker stopped", "span", "cancel_cause") {
+		t.Errorf("unexpected end record: %v", ch.Last())
+	}
+}
+
+func TestBudgetNoEventAfterEnd(t *testing.T) {
+	for range 100 {
+		ch := collect.NewTestHandler(slog.LevelDebug, false, false, true)
+		tracer := NewTracer(slog.New(ch))
+
+		span, _ := tracer.Start(context.Background(), "build", WithBudget(time.Microsecond))
+		span.End()
+		time.Sleep(100 * time.Microsecond)
+
+		if msg, _ := ch.Last()[slog.MessageKey].(string); !strings.HasPrefix(msg, "span build finished") {
+			t.Fatalf("record logged after the end record: %s", ch.String())
+		}
+	}
+}
+
+func TestBudgetFinishedTime(t *testing.T) {
+	exporter := NewInMemoryExporter()
+	processor := NewBatchSpanProcessor(exporter, BatchSpanProcessorConfig{})
+	defer processor.CloseWithTimeout(time.Second)
+	tracer := NewTracer(slog.New(&NoopHandler{}), WithSpanProcessor(processor))
+
+	now := time.Now()
+	span, _ := tracer.Start(context.Background(), "build", WithBudget(time.Hour), WithStartTime(now))
+	span.End("finished", now.Add(2*time.Hour))
+
+	if err := processor.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	spans := exporter.Spans()
+	if len(spans) != 1 || !slices.ContainsFunc(spans[0].Attributes, func(a slog.Attr) bool {
+		return a.Equal(slog.Bool("over_budget", true))
+	}) {
+		t.Errorf("unexpected spans %v", spans)
+	}
+}
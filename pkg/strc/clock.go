@@ -0,0 +1,47 @@
+package strc
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// Clock provides the current time to a Tracer. See collect.FakeClock for a deterministic
+// clock for tests.
+type Clock interface {
+	Now() time.Time
+}
+
+// WithClock is a TracerOption that sets a clock used for start, event and finish times and
+// span durations. Special "started", "at" and "finished" arguments override the clock.
+//
+// When a clock is set, records are logged with the span times instead of the time of the log
+// call, so the output is deterministic with a fake clock. Start and end times of span data
+// passed to span processors come from the clock too, only budget timers and processor timers
+// (for example the batch export interval) use the wall clock.
+func WithClock(c Clock) TracerOption {
+	return func(t *Tracer) {
+		t.clock = c
+	}
+}
+
+// now returns the current time from the tracer clock.
+func (t *Tracer) now() time.Time {
+	if t.clock != nil {
+		return t.clock.Now()
+	}
+
+	return time.Now()
+}
+
+// log logs a span record, the record time is the given time when a clock is set.
+func (t *Tracer) log(ctx context.Context, logger *slog.Logger, level slog.Level, at time.Time, msg string, attrs []slog.Attr) {
+	if t.clock == nil {
+		logger.LogAttrs(ctx, level, msg, attrs...)
+		return
+	}
+
+	r := slog.NewRecord(at, level, msg, 0)
+	r.AddAttrs(attrs...)
+	_ = logger.Handler().Handle(ctx, r)
+}
@@ -0,0 +1,38 @@
+package strc
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/osbuild/logging/pkg/collect"
+)
+
+func TestClock(t *testing.T) {
+	buf := &bytes.Buffer{}
+	clock := collect.NewFakeClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), time.Second)
+	tracer := NewTracer(slog.New(slog.NewTextHandler(buf, &slog.HandlerOptions{Level: slog.LevelDebug})),
+		WithClock(clock),
+		WithIDGenerator(NewSeededIDGenerator(AlphaFormat, 0)),
+		WithTracerOptions(TracerOptions{SkipSource: true}),
+	)
+
+	span, ctx := tracer.Start(context.Background(), "root")
+	span.Event("event")
+	child, _ := tracer.Start(ctx, "child")
+	child.End()
+	clock.Advance(time.Minute)
+	span.End()
+
+	want := `time=2024-01-01T00:00:00.000Z level=DEBUG msg="span root started" span.name=root span.id=IvQORsV span.parent=0000000 span.trace=bqzcRlJahlbbBZH
+time=2024-01-01T00:00:01.000Z level=DEBUG msg="span root event event" span.name=root span.id=IvQORsV span.parent=0000000 span.trace=bqzcRlJahlbbBZH span.event=event span.at=1s
+time=2024-01-01T00:00:02.000Z level=DEBUG msg="span child started" span.name=child span.id=kYcTpgn span.parent=IvQORsV span.trace=bqzcRlJahlbbBZH
+time=2024-01-01T00:00:03.000Z level=DEBUG msg="span child finished in 1s" span.name=child span.id=kYcTpgn span.parent=IvQORsV span.trace=bqzcRlJahlbbBZH span.dur=1s
+time=2024-01-01T00:01:04.000Z level=DEBUG msg="span root finished in 1m4s" span.name=root span.id=IvQORsV span.parent=0000000 span.trace=bqzcRlJahlbbBZH span.dur=1m4s
+`
+	if buf.String() != want {
+		t.Errorf("unexpected output:\n%s\nwant:\n%s", buf.String(), want)
+	}
+}
@@ -0,0 +1,210 @@
+package strc
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"maps"
+	"os"
+	"os/exec"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// Cmd is a traced wrapper around exec.Cmd. Starting the command starts a child span, trace
+// and span IDs are passed to the subprocess via environment variables, see FromEnvironment.
+// Standard output and error are logged line by line as records carrying the trace ID unless
+// they were set. The span ends with the exit code when the command finishes.
+//
+// The underlying exec.Cmd can be configured before the command is started, but only Run,
+// Start and Wait of Cmd are traced.
+type Cmd struct {
+	// Cmd is the underlying command.
+	Cmd *exec.Cmd
+
+	ctx    context.Context
+	tracer *Tracer
+	span   *Span
+	stdout *lineLogger
+	stderr *lineLogger
+}
+
+// Command returns a traced command to execute the named program with the given arguments.
+// The context is used to kill the process, see exec.CommandContext.
+//
+//	cmd := strc.Command(ctx, "osbuild", "--version")
+//	err := cmd.Run()
+func Command(ctx context.Context, name string, args ...string) *Cmd {
+	return tracer.Load().Command(ctx, name, args...)
+}
+
+// Command returns a traced command, see strc.Command for more information.
+func (t *Tracer) Command(ctx context.Context, name string, args ...string) *Cmd {
+	return &Cmd{
+		Cmd:    exec.CommandContext(ctx, name, args...),
+		ctx:    ctx,
+		tracer: t,
+	}
+}
+
+// Start starts a span and the command. When the command fails to start, the span is ended
+// with the error.
+func (c *Cmd) Start() error {
+	return c.start(callerPtr(2))
+}
+
+// start starts the span with the source of the Start or Run caller
+func (c *Cmd) start(source string) error {
+	c.span, c.ctx = c.tracer.Start(c.ctx, "exec "+c.Cmd.Args[0], "args", c.Cmd.Args[1:], withSource(source))
+	// the span does not continue in this goroutine and Wait can be called from another one
+	c.span.detach()
+
+	if c.Cmd.Env == nil {
+		c.Cmd.Env = os.Environ()
+	}
+	env := envCarrier{}
+	Inject(c.ctx, env)
+	for _, k := range slices.Sorted(maps.Keys(env)) {
+		c.Cmd.Env = append(c.Cmd.Env, k+"="+env[k])
+	}
+
+	if c.Cmd.Stdout == nil {
+		c.stdout = &lineLogger{ctx: c.ctx, logger: c.tracer.base, cmd: c.Cmd.Args[0], stream: "stdout"}
+		c.Cmd.Stdout = c.stdout
+	}
+	if c.Cmd.Stderr == nil {
+		c.stderr = &lineLogger{ctx: c.ctx, logger: c.tracer.base, cmd: c.Cmd.Args[0], stream: "stderr"}
+		c.Cmd.Stderr = c.stderr
+	}
+
+	if err := c.Cmd.Start(); err != nil {
+		c.span.EndWithError(err)
+		return err
+	}
+
+	return nil
+}
+
+// Wait waits for the command to exit, logs remaining output and ends the span with "exit_code"
+// argument. Non-zero exit codes are recorded as span errors.
+func (c *Cmd) Wait() error {
+	if c.span == nil {
+		return ErrCommandNotStarted
+	}
+
+	err := c.Cmd.Wait()
+	c.stdout.flush()
+	c.stderr.flush()
+
+	code := -1
+	if c.Cmd.ProcessState != nil {
+		code = c.Cmd.ProcessState.ExitCode()
+	}
+	c.span.EndWithError(err, "exit_code", code)
+
+	return err
+}
+
+// ErrCommandNotStarted is returned by Wait when the command was not started.
+var ErrCommandNotStarted = errors.New("command not started")
+
+// Run starts the command and waits for it to complete, see Start and Wait.
+func (c *Cmd) Run() error {
+	if err := c.start(callerPtr(2)); err != nil {
+		return err
+	}
+
+	return c.Wait()
+}
+
+// FromEnvironment returns a new context with trace ID and span ID passed by the parent process
+// via environment variables, see Command. Spans started from the context are children of the
+// parent process span. Returns a background context when the variables are not set.
+//
+//	func main() {
+//		span, ctx := strc.Start(strc.FromEnvironment(), "main")
+//		defer span.End()
+//	}
+func FromEnvironment() context.Context {
+	env := envCarrier{}
+	for _, kv := range os.Environ() {
+		if k, v, ok := strings.Cut(kv, "="); ok {
+			env[k] = v
+		}
+	}
+
+	return Extract(context.Background(), env)
+}
+
+// envCarrier maps propagation fields to environment variable names, for example
+// X-Strc-Trace-ID to X_STRC_TRACE_ID and traceparent to TRACEPARENT
+type envCarrier map[string]string
+
+func (c envCarrier) Get(key string) string {
+	return c[envKey(key)]
+}
+
+func (c envCarrier) Set(key, value string) {
+	c[envKey(key)] = value
+}
+
+func envKey(key string) string {
+	return strings.ToUpper(strings.ReplaceAll(key, "-", "_"))
+}
+
+// CommandLineMaxSize is the maximum size of a line logged by Cmd, longer lines are split into
+// multiple records so output without newlines does not grow memory without bound.
+var CommandLineMaxSize = 64 * 1024 // 64KB
+
+// lineLogger is an io.Writer which logs every line as a separate record
+type lineLogger struct {
+	ctx    context.Context
+	logger *slog.Logger
+	cmd    string
+	stream string
+
+	mu  sync.Mutex
+	buf []byte
+}
+
+func (l *lineLogger) Write(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.buf = append(l.buf, p...)
+	for {
+		i := bytes.IndexByte(l.buf, '\n')
+		if i < 0 {
+			break
+		}
+		l.log(l.buf[:i])
+		l.buf = l.buf[i+1:]
+	}
+	for len(l.buf) >= CommandLineMaxSize {
+		l.log(l.buf[:CommandLineMaxSize])
+		l.buf = l.buf[CommandLineMaxSize:]
+	}
+
+	return len(p), nil
+}
+
+// flush logs the last line without a newline, safe to call on nil
+func (l *lineLogger) flush() {
+	if l == nil {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if len(l.buf) > 0 {
+		l.log(l.buf)
+		l.buf = nil
+	}
+}
+
+func (l *lineLogger) log(line []byte) {
+	l.logger.InfoContext(l.ctx, string(bytes.TrimRight(line, "\r")), "cmd", l.cmd, "stream", l.stream)
+}
@@ -0,0 +1,99 @@
+package strc
+
+import (
+	"context"
+	"log/slog"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/osbuild/logging/pkg/collect"
+)
+
+func TestCommand(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+
+	ch := collect.NewTestHandler(slog.LevelDebug, false, false, false)
+	tracer := NewTracer(slog.New(NewMultiHandler(ch)))
+
+	span, ctx := tracer.Start(context.Background(), "parent")
+	defer span.End()
+
+	cmd := tracer.Command(ctx, "sh", "-c", "echo out; echo err >&2; echo $X_STRC_TRACE_ID; printf last; exit 3")
+	err := cmd.Run()
+	if err == nil {
+		t.Fatal("expected exit error")
+	}
+
+	for _, line := range []string{"out", "err", string(span.TraceID()), "last"} {
+		if !ch.Contains(line, slog.MessageKey) {
+			t.Errorf("line %q not logged: %v", line, ch.All())
+		}
+	}
+	if !ch.Contains("stderr", "stream") || !ch.Contains(string(span.TraceID()), "trace_id") {
+		t.Errorf("unexpected output records: %v", ch.All())
+	}
+
+	last := ch.Last()
+	sg := last["span"].(map[string]any)
+	if sg["name"] != "exec sh" || sg["exit_code"] != int64(3) || sg["status"] != "error" {
+		t.Errorf("unexpected end record: %v", last)
+	}
+	if sg["parent"] != SpanIDFromContext(ctx).ID() {
+		t.Errorf("command span is not a child: %v", last)
+	}
+
+	// start and end records have the source of the Run caller
+	for _, r := range ch.All() {
+		if sg, ok := r["span"].(map[string]any); ok && sg["name"] == "exec sh" {
+			if source, _ := sg["source"].(string); !strings.Contains(source, "command_test.go") {
+				t.Errorf("unexpected source %q", source)
+			}
+		}
+	}
+}
+
+func TestCommandLongLine(t *testing.T) {
+	defer func(size int) { CommandLineMaxSize = size }(CommandLineMaxSize)
+	CommandLineMaxSize = 4
+
+	ch := collect.NewTestHandler(slog.LevelDebug, false, false, false)
+	l := &lineLogger{ctx: context.Background(), logger: slog.New(ch), cmd: "cmd", stream: "stdout"}
+	_, _ = l.Write([]byte("abcdefghij"))
+	_, _ = l.Write([]byte("k\nxy"))
+	l.flush()
+
+	for _, line := range []string{"abcd", "efgh", "ijk", "xy"} {
+		if !ch.Contains(line, slog.MessageKey) {
+			t.Errorf("line %q not logged: %v", line, ch.All())
+		}
+	}
+	if ch.Count() != 4 {
+		t.Errorf("unexpected records: %v", ch.All())
+	}
+}
+
+func TestCommandNotFound(t *testing.T) {
+	ch := collect.NewTestHandler(slog.LevelDebug, false, false, false)
+	tracer := NewTracer(slog.New(NewMultiHandler(ch)))
+
+	if err := tracer.Command(context.Background(), "/nonexistent/command").Run(); err == nil {
+		t.Fatal("expected error")
+	}
+	if !ch.Contains("error", "span", "status") {
+		t.Errorf("span not failed: %v", ch.All())
+	}
+}
+
+func TestFromEnvironment(t *testing.T) {
+	t.Setenv("X_STRC_TRACE_ID", "bqzcRlJahlbbBZH")
+	t.Setenv("X_STRC_SPAN_ID", "0000000.IvQORsV")
+	t.Setenv("TRACEPARENT", "")
+
+	ctx := FromEnvironment()
+	if TraceIDFromContext(ctx) != "bqzcRlJahlbbBZH" || SpanIDFromContext(ctx) != "0000000.IvQORsV" {
+		t.Errorf("unexpected context %s %s", TraceIDFromContext(ctx), SpanIDFromContext(ctx))
+	}
+}
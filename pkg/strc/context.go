@@ -2,18 +2,21 @@ package strc
 
 import (
 	"context"
-	"math/rand"
+	"log/slog"
 	"net/http"
 	"strings"
-	"time"
 )
 
 type key int
 
 const (
-	traceIDKey key = iota
-	spanIDKey  key = iota
-	spanKey    key = iota
+	traceIDKey    key = iota
+	spanIDKey     key = iota
+	spanKey       key = iota
+	traceFlagsKey key = iota
+	traceStateKey key = iota
+	loggerKey     key = iota
+	attemptsKey   key = iota
 
 	traceLength = 15 // ojtlqPCGXEWytHg
 	spanLength  = 7  // aCBzdka.NjPdyjv
@@ -36,9 +39,9 @@ func (t TraceID) String() string {
 	return string(t)
 }
 
-// NewTraceID generates a new random trace ID.
+// NewTraceID generates a new random trace ID via the package ID generator.
 func NewTraceID() TraceID {
-	return TraceID(randString(traceLength))
+	return idGenerator.Load().NewTraceID()
 }
 
 // SpanID is a unique identifier for a trace.
@@ -52,17 +55,22 @@ func (s SpanID) String() string {
 	return string(s)
 }
 
+// ParentID returns the parent part of the span ID.
 func (s SpanID) ParentID() string {
-	return string(s)[:spanLength]
+	parent, _, _ := strings.Cut(s.String(), ".")
+	return parent
 }
 
+// ID returns the ID part of the span ID.
 func (s SpanID) ID() string {
-	return string(s)[spanLength+1 : spanLength*2+1]
+	_, id, _ := strings.Cut(s.String(), ".")
+	return id
 }
 
-// NewSpanID generates a new span ID. Uses context to fetch its parent span ID.
+// NewSpanID generates a new span ID via the package ID generator. Uses context to fetch
+// its parent span ID.
 func NewSpanID(ctx context.Context) SpanID {
-	return SpanID(SpanIDFromContext(ctx).ID() + "." + randString(spanLength))
+	return idGenerator.Load().NewSpanID(SpanIDFromContext(ctx))
 }
 
 // Start returns trace ID from a context. It returns EmptyTraceID if trace ID is not found.
@@ -84,8 +92,15 @@ func WithTraceID(ctx context.Context, traceID TraceID) context.Context {
 	return context.WithValue(ctx, traceIDKey, traceID)
 }
 
-// TraceIDFromRequest returns trace ID from a request. If trace ID is not found, it returns EmptyTraceID.
+// TraceIDFromRequest returns trace ID from a request. Both X-Strc-Trace-ID and W3C traceparent
+// headers are supported, X-Strc-Trace-ID is used when both are present. If trace ID is not found,
+// it returns EmptyTraceID.
 func TraceIDFromRequest(req *http.Request) TraceID {
+	if useW3C(HeaderCarrier(req.Header), PreferStrc) {
+		tid, _, _, _ := TraceparentFromRequest(req)
+		return tid
+	}
+
 	t := req.Header.Get(TraceHTTPHeaderName)
 	if t == "" {
 		return EmptyTraceID
@@ -139,8 +154,15 @@ func WithSpan(ctx context.Context, span *Span) context.Context {
 	return context.WithValue(ctx, spanKey, span)
 }
 
-// SpanIDFromRequest returns span ID from a request. If span ID is not found, it returns EmptySpanID.
+// SpanIDFromRequest returns span ID from a request. Both X-Strc-Span-ID and W3C traceparent
+// headers are supported, X-Strc headers are used when both are present. If span ID is not found,
+// it returns EmptySpanID.
 func SpanIDFromRequest(req *http.Request) SpanID {
+	if useW3C(HeaderCarrier(req.Header), PreferStrc) {
+		_, sid, _, _ := TraceparentFromRequest(req)
+		return sid
+	}
+
 	s := req.Header.Get(SpanHTTPHeaderName)
 	if s == "" {
 		return EmptySpanID
@@ -157,30 +179,46 @@ func AddSpanIDHeader(ctx context.Context, req *http.Request) {
 	}
 }
 
-const (
-	letterBytes   = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ"
-	letterIdxBits = 6                    // 6 bits to represent a letter index
-	letterIdxMask = 1<<letterIdxBits - 1 // All 1-bits, as many as letterIdxBits
-	letterIdxMax  = 63 / letterIdxBits   // # of letter indices fitting in 63 bits
-)
+// SpanContext identifies a span within a trace, it is used for links between spans. It can be
+// serialized to JSON and embedded in job arguments, it implements TextMapCarrier for Inject and
+// Extract.
+type SpanContext struct {
+	TraceID TraceID    `json:"trace"`
+	SpanID  SpanID     `json:"span"`
+	Flags   TraceFlags `json:"flags"`
+	State   string     `json:"state,omitempty"`
+}
+
+// IsValid returns true when both trace ID and span ID are set.
+func (sc SpanContext) IsValid() bool {
+	return sc.TraceID != "" && sc.TraceID != EmptyTraceID && sc.SpanID != "" && sc.SpanID != EmptySpanID
+}
+
+// SpanContextFromContext returns span context of the current span in a context.
+func SpanContextFromContext(ctx context.Context) SpanContext {
+	return SpanContext{
+		TraceID: TraceIDFromContext(ctx),
+		SpanID:  SpanIDFromContext(ctx),
+		Flags:   TraceFlagsFromContext(ctx),
+		State:   TraceStateFromContext(ctx),
+	}
+}
+
+// LoggerFromContext returns logger from a context set by ContextSetLogger or WithLogger. It
+// returns slog.Default() if logger was not found.
+func LoggerFromContext(ctx context.Context) *slog.Logger {
+	if ctx == nil {
+		return slog.Default()
+	}
+
+	if v := ctx.Value(loggerKey); v != nil {
+		return v.(*slog.Logger)
+	}
+
+	return slog.Default()
+}
 
-var src = rand.NewSource(time.Now().UnixNano())
-
-func randString(n int) string {
-	// decently fast random string generator
-	sb := strings.Builder{}
-	sb.Grow(n)
-	for i, cache, remain := n-1, src.Int63(), letterIdxMax; i >= 0; {
-		if remain == 0 {
-			cache, remain = src.Int63(), letterIdxMax
-		}
-		if idx := int(cache & letterIdxMask); idx < len(letterBytes) {
-			sb.WriteByte(letterBytes[idx])
-			i--
-		}
-		cache >>= letterIdxBits
-		remain--
-	}
-
-	return sb.String()
+// WithLogger returns a new context with logger.
+func WithLogger(ctx context.Context, logger *slog.Logger) context.Context {
+	return context.WithValue(ctx, loggerKey, logger)
 }
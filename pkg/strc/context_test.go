@@ -2,14 +2,13 @@ package strc
 
 import (
 	"context"
-	"math/rand"
 	"net/http"
 	"strings"
 	"testing"
 )
 
 func TestTraceID(t *testing.T) {
-	src = rand.NewSource(0)
+	SetIDGenerator(NewSeededIDGenerator(AlphaFormat, 0))
 	var tid TraceID
 	r := &http.Request{
 		Header: http.Header{},
@@ -60,7 +59,7 @@ func TestTraceID(t *testing.T) {
 }
 
 func TestSpanID(t *testing.T) {
-	src = rand.NewSource(0)
+	SetIDGenerator(NewSeededIDGenerator(AlphaFormat, 0))
 	var sid SpanID
 	r := &http.Request{
 		Header: http.Header{},
@@ -0,0 +1,487 @@
+package strc
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+)
+
+// DefaultLatencyBuckets are the default upper bounds of latency buckets of DebugPages.
+var DefaultLatencyBuckets = []time.Duration{
+	10 * time.Microsecond,
+	100 * time.Microsecond,
+	time.Millisecond,
+	10 * time.Millisecond,
+	100 * time.Millisecond,
+	time.Second,
+	10 * time.Second,
+	100 * time.Second,
+}
+
+const (
+	// DefaultMaxTraces is the default size of the ring buffer of recently completed traces.
+	DefaultMaxTraces = 100
+
+	// DefaultMaxOpenSpans is the default maximum number of tracked open spans.
+	DefaultMaxOpenSpans = 10000
+
+	// DefaultMaxTraceSpans is the default maximum number of spans kept for a single trace.
+	DefaultMaxTraceSpans = 1000
+
+	// DefaultSlowTraces is the default number of slowest traces shown.
+	DefaultSlowTraces = 10
+
+	// DefaultPendingTimeout is the default time after which traces with open spans are
+	// completed with the spans available.
+	DefaultPendingTimeout = 10 * time.Minute
+)
+
+// DebugPagesConfig is the configuration for DebugPages.
+type DebugPagesConfig struct {
+	// MaxTraces is the size of the ring buffer of recently completed traces. Defaults to
+	// DefaultMaxTraces.
+	MaxTraces int
+
+	// MaxOpenSpans is the maximum number of tracked open spans, spans started over the limit
+	// are not tracked. Defaults to DefaultMaxOpenSpans.
+	MaxOpenSpans int
+
+	// MaxTraceSpans is the maximum number of spans kept for a single trace, further spans
+	// are dropped. Defaults to DefaultMaxTraceSpans.
+	MaxTraceSpans int
+
+	// SlowTraces is the number of slowest recent traces shown. Defaults to DefaultSlowTraces.
+	SlowTraces int
+
+	// Buckets are upper bounds of latency buckets in increasing order. Defaults to
+	// DefaultLatencyBuckets.
+	Buckets []time.Duration
+
+	// MaxSeries is the maximum number of span names in the latency table including the
+	// overflow row. Spans with new names over the limit are counted with OverflowSpanName name.
+	// Defaults to DefaultMaxSeries.
+	MaxSeries int
+
+	// PendingTimeout is the time after which traces with spans that never ended are completed
+	// with the spans available, so they do not hold memory forever. Open spans are still
+	// tracked. Defaults to DefaultPendingTimeout.
+	PendingTimeout time.Duration
+
+	// TracerOptions are options of the tracer the debug pages are registered with, the trace
+	// ID field key is used by LogOpenSpans. See WithTracerOptions.
+	TracerOptions TracerOptions
+}
+
+// DebugPages is a SpanProcessor which tracks open spans, keeps a ring buffer of recently
+// completed traces and latency buckets of finished spans by name. It is an http.Handler which
+// serves a plain text (zpages-style) page with open spans, slowest recent traces and latencies.
+//
+//	debug := strc.NewDebugPages(strc.DebugPagesConfig{})
+//	strc.SetLogger(logger, strc.WithSpanProcessor(debug))
+//	http.Handle("/debug/strc", debug)
+//
+// Open spans which context was cancelled are flagged as such, use LogOpenSpans before the
+// process exits to report spans which were never ended. Only sampled spans are tracked.
+type DebugPages struct {
+	config DebugPagesConfig
+
+	mu      sync.Mutex
+	open    map[SpanID]*openSpan
+	pending map[TraceID]*pendingTrace
+	ring    []*debugTrace
+	next    int
+	latency map[string][]uint64
+	swept   time.Time
+
+	// droppedOpen counts spans over MaxOpenSpans, droppedSpans spans over MaxTraceSpans
+	droppedOpen  uint64
+	droppedSpans uint64
+}
+
+var _ SpanProcessor = (*DebugPages)(nil)
+var _ SpanStartProcessor = (*DebugPages)(nil)
+var _ http.Handler = (*DebugPages)(nil)
+
+// OpenSpan is a span which was started but not ended yet.
+type OpenSpan struct {
+	// TraceID is the trace ID of the span.
+	TraceID TraceID
+
+	// SpanID is the span ID.
+	SpanID SpanID
+
+	// Name is the name of the span.
+	Name string
+
+	// Start is the start time of the span.
+	Start time.Time
+
+	// Source is the file and line where the span was started.
+	Source string
+
+	// Cancelled is true when the span context was cancelled before the span ended.
+	Cancelled bool
+}
+
+type openSpan struct {
+	OpenSpan
+	stop func() bool
+}
+
+type pendingTrace struct {
+	created time.Time
+	open    int
+	spans   []debugSpan
+}
+
+type debugSpan struct {
+	SpanData
+	cancelled bool
+}
+
+type debugTrace struct {
+	id       TraceID
+	duration time.Duration
+	spans    []debugSpan
+}
+
+// NewDebugPages creates new debug pages.
+func NewDebugPages(config DebugPagesConfig) *DebugPages {
+	if config.MaxTraces <= 0 {
+		config.MaxTraces = DefaultMaxTraces
+	}
+	if config.MaxOpenSpans <= 0 {
+		config.MaxOpenSpans = DefaultMaxOpenSpans
+	}
+	if config.MaxTraceSpans <= 0 {
+		config.MaxTraceSpans = DefaultMaxTraceSpans
+	}
+	if config.SlowTraces <= 0 {
+		config.SlowTraces = DefaultSlowTraces
+	}
+	if len(config.Buckets) == 0 {
+		config.Buckets = DefaultLatencyBuckets
+	}
+	if config.MaxSeries <= 0 {
+		config.MaxSeries = DefaultMaxSeries
+	}
+	if config.PendingTimeout <= 0 {
+		config.PendingTimeout = DefaultPendingTimeout
+	}
+
+	return &DebugPages{
+		config:  config,
+		open:    make(map[SpanID]*openSpan),
+		pending: make(map[TraceID]*pendingTrace),
+		ring:    make([]*debugTrace, config.MaxTraces),
+		latency: make(map[string][]uint64),
+		swept:   time.Now(),
+	}
+}
+
+// OnStart tracks the started span as open.
+func (d *DebugPages) OnStart(span *Span) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.sweep()
+	if len(d.open) >= d.config.MaxOpenSpans {
+		d.droppedOpen++
+		return
+	}
+
+	o := &openSpan{
+		OpenSpan: OpenSpan{
+			TraceID: span.tid,
+			SpanID:  span.sid,
+			Name:    span.name,
+			Start:   span.started,
+			Source:  span.source,
+		},
+	}
+	o.stop = context.AfterFunc(span.ctx, func() {
+		d.mu.Lock()
+		defer d.mu.Unlock()
+
+		o.Cancelled = true
+	})
+	d.open[span.sid] = o
+
+	pt, ok := d.pending[span.tid]
+	if !ok {
+		pt = &pendingTrace{created: time.Now()}
+		d.pending[span.tid] = pt
+	}
+	pt.open++
+}
+
+// OnEnd removes the span from open spans and completes the trace when it has no open spans.
+func (d *DebugPages) OnEnd(span SpanData) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.sweep()
+	d.observe(span)
+
+	o, tracked := d.open[span.SpanID]
+	if tracked {
+		o.stop()
+		delete(d.open, span.SpanID)
+	}
+
+	pt, ok := d.pending[span.TraceID]
+	if !ok {
+		pt = &pendingTrace{created: time.Now()}
+	}
+	if tracked {
+		pt.open--
+	}
+	if len(pt.spans) < d.config.MaxTraceSpans {
+		pt.spans = append(pt.spans, debugSpan{SpanData: span, cancelled: tracked && o.Cancelled})
+	} else {
+		d.droppedSpans++
+	}
+
+	if pt.open > 0 {
+		d.pending[span.TraceID] = pt
+		return
+	}
+	delete(d.pending, span.TraceID)
+	d.complete(span.TraceID, pt.spans)
+}
+
+func (d *DebugPages) observe(span SpanData) {
+	name := span.Name
+	counts, ok := d.latency[name]
+	if !ok {
+		// room for the overflow row is reserved
+		reserved := 1
+		if _, found := d.latency[OverflowSpanName]; found {
+			reserved = 0
+		}
+		if len(d.latency)+reserved >= d.config.MaxSeries {
+			name = OverflowSpanName
+			counts = d.latency[name]
+		}
+		if counts == nil {
+			counts = make([]uint64, len(d.config.Buckets)+1)
+			d.latency[name] = counts
+		}
+	}
+
+	i, _ := slices.BinarySearch(d.config.Buckets, span.End.Sub(span.Start))
+	counts[i]++
+}
+
+// sweep completes pending traces older than PendingTimeout, it runs at most once per
+// PendingTimeout and must be called with the lock held.
+func (d *DebugPages) sweep() {
+	now := time.Now()
+	if now.Sub(d.swept) < d.config.PendingTimeout {
+		return
+	}
+	d.swept = now
+
+	deadline := now.Add(-d.config.PendingTimeout)
+	for id, pt := range d.pending {
+		if pt.created.After(deadline) {
+			continue
+		}
+
+		delete(d.pending, id)
+		if len(pt.spans) > 0 {
+			d.complete(id, pt.spans)
+		}
+	}
+}
+
+func (d *DebugPages) complete(id TraceID, spans []debugSpan) {
+	start, end := spans[0].Start, spans[0].End
+	for _, s := range spans[1:] {
+		if s.Start.Before(start) {
+			start = s.Start
+		}
+		if s.End.After(end) {
+			end = s.End
+		}
+	}
+
+	d.ring[d.next] = &debugTrace{id: id, duration: end.Sub(start), spans: spans}
+	d.next = (d.next + 1) % len(d.ring)
+}
+
+// OpenSpans returns all tracked open spans, oldest first.
+func (d *DebugPages) OpenSpans() []OpenSpan {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	result := make([]OpenSpan, 0, len(d.open))
+	for _, o := range d.open {
+		result = append(result, o.OpenSpan)
+	}
+	slices.SortFunc(result, func(a, b OpenSpan) int {
+		return a.Start.Compare(b.Start)
+	})
+
+	return result
+}
+
+// LogOpenSpans logs a warning for every open span, it is meant to be called before the process
+// exits to report spans which were never ended. Returns the number of open spans.
+func (d *DebugPages) LogOpenSpans(logger *slog.Logger) int {
+	spans := d.OpenSpans()
+	traceIDKey := d.config.TracerOptions.traceIDFieldKey()
+	for _, o := range spans {
+		logger.Warn("span not ended",
+			slog.String("name", o.Name),
+			slog.String(traceIDKey, o.TraceID.String()),
+			slog.String(SpanIDKey, o.SpanID.String()),
+			slog.Duration("age", time.Since(o.Start)),
+			slog.String(slog.SourceKey, o.Source),
+			slog.Bool("cancelled", o.Cancelled),
+		)
+	}
+
+	return len(spans)
+}
+
+// ServeHTTP writes open spans, slowest recent traces and latency buckets as plain text.
+func (d *DebugPages) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+
+	d.writeOpenSpans(w)
+	d.writeTraces(w)
+	d.writeLatency(w)
+}
+
+func (d *DebugPages) writeOpenSpans(w io.Writer) {
+	spans := d.OpenSpans()
+	now := time.Now()
+
+	d.mu.Lock()
+	dropped := d.droppedOpen
+	d.mu.Unlock()
+
+	fmt.Fprintf(w, "Open spans: %d (not tracked over limit: %d)\n\n", len(spans), dropped)
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "AGE\tNAME\tTRACE\tSPAN\tSOURCE\tFLAGS")
+	for _, o := range spans {
+		flags := ""
+		if o.Cancelled {
+			flags = "cancelled"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n", formatAge(now.Sub(o.Start)), o.Name, o.TraceID, o.SpanID.ID(), o.Source, flags)
+	}
+	tw.Flush()
+}
+
+func (d *DebugPages) writeTraces(w io.Writer) {
+	d.mu.Lock()
+	traces := make([]*debugTrace, 0, len(d.ring))
+	for _, t := range d.ring {
+		if t != nil {
+			traces = append(traces, t)
+		}
+	}
+	dropped := d.droppedSpans
+	d.mu.Unlock()
+
+	slices.SortStableFunc(traces, func(a, b *debugTrace) int {
+		return int(b.duration - a.duration)
+	})
+	n := len(traces)
+	traces = traces[:min(len(traces), d.config.SlowTraces)]
+
+	fmt.Fprintf(w, "\nSlowest recent traces: %d of %d (spans dropped over trace limit: %d)\n", len(traces), n, dropped)
+	for _, t := range traces {
+		fmt.Fprintf(w, "\ntrace %s %s\n", t.id, formatAge(t.duration))
+
+		ids := make(map[string]bool, len(t.spans))
+		children := make(map[string][]debugSpan, len(t.spans))
+		for _, s := range t.spans {
+			ids[s.SpanID.ID()] = true
+		}
+		var roots []debugSpan
+		for _, s := range t.spans {
+			if parent := s.SpanID.ParentID(); ids[parent] {
+				children[parent] = append(children[parent], s)
+			} else {
+				roots = append(roots, s)
+			}
+		}
+
+		writeSpanTree(w, roots, children, 1)
+	}
+}
+
+func writeSpanTree(w io.Writer, spans []debugSpan, children map[string][]debugSpan, depth int) {
+	slices.SortStableFunc(spans, func(a, b debugSpan) int {
+		return a.Start.Compare(b.Start)
+	})
+
+	for _, s := range spans {
+		var flags []string
+		if s.Status == StatusError {
+			flags = append(flags, "error")
+		}
+		if s.cancelled {
+			flags = append(flags, "ended after cancel")
+		}
+
+		fmt.Fprintf(w, "%s%s %s", strings.Repeat("  ", depth), s.Name, formatAge(s.End.Sub(s.Start)))
+		if len(flags) > 0 {
+			fmt.Fprintf(w, " [%s]", strings.Join(flags, ", "))
+		}
+		if s.Source != "" {
+			fmt.Fprintf(w, " %s", s.Source)
+		}
+		fmt.Fprintln(w)
+
+		writeSpanTree(w, children[s.SpanID.ID()], children, depth+1)
+	}
+}
+
+func (d *DebugPages) writeLatency(w io.Writer) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	names := make([]string, 0, len(d.latency))
+	for name := range d.latency {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	fmt.Fprintf(w, "\nLatency of finished spans\n\n")
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprint(tw, "NAME\t")
+	for _, le := range d.config.Buckets {
+		fmt.Fprintf(tw, "<=%s\t", le)
+	}
+	fmt.Fprintf(tw, ">%s\t\n", d.config.Buckets[len(d.config.Buckets)-1])
+	for _, name := range names {
+		fmt.Fprintf(tw, "%s\t", name)
+		for _, c := range d.latency[name] {
+			fmt.Fprintf(tw, "%d\t", c)
+		}
+		fmt.Fprintln(tw)
+	}
+	tw.Flush()
+}
+
+func formatAge(d time.Duration) string {
+	switch {
+	case d >= time.Second:
+		return d.Round(time.Millisecond).String()
+	case d >= time.Millisecond:
+		return d.Round(time.Microsecond).String()
+	default:
+		return d.String()
+	}
+}
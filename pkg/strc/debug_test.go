@@ -0,0 +1,155 @@
+package strc
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDebugPages(t *testing.T) {
+	opts := TracerOptions{TraceIDFieldKey: "tid"}
+	debug := NewDebugPages(DebugPagesConfig{TracerOptions: opts})
+	tracer := NewTracer(slog.New(&NoopHandler{}), WithSpanProcessor(debug), WithTracerOptions(opts))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	now := time.Now()
+	root, ctx := tracer.Start(ctx, "root", WithStartTime(now.Add(-time.Second)))
+	child, _ := tracer.Start(ctx, "child", WithStartTime(now.Add(-500*time.Millisecond)))
+
+	open := debug.OpenSpans()
+	if len(open) != 2 || open[0].Name != "root" || open[1].Name != "child" || open[0].Cancelled {
+		t.Fatalf("unexpected open spans %+v", open)
+	}
+
+	cancel()
+	deadline := time.Now().Add(5 * time.Second)
+	for !debug.OpenSpans()[1].Cancelled {
+		if time.Now().After(deadline) {
+			t.Fatal("span was not flagged as cancelled")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	buf := &bytes.Buffer{}
+	if n := debug.LogOpenSpans(slog.New(slog.NewTextHandler(buf, nil))); n != 2 {
+		t.Errorf("expected 2 open spans, got %d", n)
+	}
+	if !strings.Contains(buf.String(), `msg="span not ended" name=child`) || !strings.Contains(buf.String(), "cancelled=true") || !strings.Contains(buf.String(), "tid="+string(root.TraceID())) {
+		t.Errorf("unexpected log output:\n%s", buf.String())
+	}
+
+	child.End("finished", now.Add(-400*time.Millisecond))
+	root.End("finished", now)
+
+	if len(debug.OpenSpans()) != 0 {
+		t.Errorf("unexpected open spans %+v", debug.OpenSpans())
+	}
+
+	rec := httptest.NewRecorder()
+	debug.ServeHTTP(rec, httptest.NewRequest("GET", "/debug/strc", nil))
+
+	body := rec.Body.String()
+	for _, line := range []string{
+		"Open spans: 0 (not tracked over limit: 0)\n",
+		"Slowest recent traces: 1 of 1 (spans dropped over trace limit: 0)\n",
+		"\n  root 1s [ended after cancel]",
+		"\n    child 100ms [ended after cancel]",
+		"Latency of finished spans\n",
+	} {
+		if !strings.Contains(body, line) {
+			t.Errorf("%q not found in:\n%s", line, body)
+		}
+	}
+}
+
+func TestDebugPagesSlowestTraces(t *testing.T) {
+	debug := NewDebugPages(DebugPagesConfig{MaxTraces: 3, SlowTraces: 2})
+
+	now := time.Now()
+	for i, d := range []time.Duration{4, 1, 3, 2} {
+		debug.OnEnd(SpanData{
+			TraceID: TraceID(strings.Repeat(string(rune('a'+i)), 15)),
+			SpanID:  "0000000.aaaaaaa",
+			Name:    "trace",
+			Start:   now,
+			End:     now.Add(d * time.Millisecond),
+		})
+	}
+
+	rec := httptest.NewRecorder()
+	debug.ServeHTTP(rec, httptest.NewRequest("GET", "/debug/strc", nil))
+
+	// the 4ms trace was overwritten in the ring buffer
+	body := rec.Body.String()
+	if !strings.Contains(body, "Slowest recent traces: 2 of 3 (spans dropped over trace limit: 0)\n\ntrace ccccccccccccccc 3ms\n") ||
+		!strings.Contains(body, "\ntrace ddddddddddddddd 2ms\n") ||
+		strings.Contains(body, "bbbbbbbbbbbbbbb") {
+		t.Errorf("unexpected output:\n%s", body)
+	}
+}
+
+func TestDebugPagesLimits(t *testing.T) {
+	debug := NewDebugPages(DebugPagesConfig{MaxOpenSpans: 1, MaxTraceSpans: 1, Buckets: []time.Duration{time.Millisecond}})
+	tracer := NewTracer(slog.New(&NoopHandler{}), WithSpanProcessor(debug))
+
+	now := time.Now()
+	root, ctx := tracer.Start(context.Background(), "root", WithStartTime(now))
+	child, _ := tracer.Start(ctx, "child", WithStartTime(now))
+	child.End("finished", now.Add(time.Millisecond))
+	root.End("finished", now.Add(2*time.Millisecond))
+
+	rec := httptest.NewRecorder()
+	debug.ServeHTTP(rec, httptest.NewRequest("GET", "/debug/strc", nil))
+	body := rec.Body.String()
+	for _, line := range []string{
+		"Open spans: 0 (not tracked over limit: 1)\n",
+		"Slowest recent traces: 1 of 1 (spans dropped over trace limit: 1)\n",
+	} {
+		if !strings.Contains(body, line) {
+			t.Errorf("%q not found in:\n%s", line, body)
+		}
+	}
+
+	// a duration equal to the bound falls into the bucket
+	if !regexp.MustCompile(`<=1ms +>1ms *\n +child +1 +0 *\n +root +0 +1`).MatchString(body) {
+		t.Errorf("unexpected latency table:\n%s", body)
+	}
+}
+
+func TestDebugPagesPendingTimeout(t *testing.T) {
+	debug := NewDebugPages(DebugPagesConfig{PendingTimeout: time.Millisecond, MaxSeries: 2})
+	tracer := NewTracer(slog.New(&NoopHandler{}), WithSpanProcessor(debug))
+
+	root, ctx := tracer.Start(context.Background(), "never-ended")
+	child, _ := tracer.Start(ctx, "child")
+	child.End()
+
+	time.Sleep(5 * time.Millisecond)
+	other, _ := tracer.Start(context.Background(), "other-trace")
+	other.End()
+
+	debug.mu.Lock()
+	pending := len(debug.pending)
+	latency := len(debug.latency)
+	debug.mu.Unlock()
+	if pending != 0 {
+		t.Errorf("expected no pending traces, got %d", pending)
+	}
+	if latency != 2 {
+		t.Errorf("expected latency rows within MaxSeries, got %d", latency)
+	}
+
+	// the open span is still tracked and the expired trace is shown
+	rec := httptest.NewRecorder()
+	debug.ServeHTTP(rec, httptest.NewRequest("GET", "/debug/strc", nil))
+	body := rec.Body.String()
+	if !strings.Contains(body, "Open spans: 1 ") || !strings.Contains(body, "Slowest recent traces: 2 of 2") {
+		t.Errorf("unexpected output:\n%s", body)
+	}
+	root.End()
+}
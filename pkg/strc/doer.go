@@ -1,9 +1,7 @@
 package strc
 
 import (
-	"log/slog"
 	"net/http"
-	"strings"
 )
 
 // DoerErr is a simple wrapped error without any message. Additional message would
@@ -30,106 +28,35 @@ type HttpRequestDoer interface {
 	Do(req *http.Request) (*http.Response, error)
 }
 
-// TracingDoer is a http client doer that adds tracing to the request and response.
+// TracingDoer is a http client doer that adds tracing to the request and response. It is a thin
+// wrapper over Transport, errors are wrapped in DoerErr. The span source is the caller of Do
+// and the span ends when the response body is closed.
 type TracingDoer struct {
-	doer   HttpRequestDoer
-	config TracingDoerConfig
+	doer      HttpRequestDoer
+	transport *Transport
 }
 
-type TracingDoerConfig struct {
-	WithRequestBody     bool
-	WithResponseBody    bool
-	WithRequestHeaders  bool
-	WithResponseHeaders bool
-}
+// TracingDoerConfig is the configuration of TracingDoer, see TransportConfig.
+type TracingDoerConfig = TransportConfig
 
 // NewTracingDoer returns a new TracingDoer.
 func NewTracingDoer(doer HttpRequestDoer) *TracingDoer {
-	client := TracingDoer{
-		doer: doer,
-	}
-	return &client
+	return NewTracingDoerWithConfig(doer, TracingDoerConfig{})
 }
 
 func NewTracingDoerWithConfig(doer HttpRequestDoer, config TracingDoerConfig) *TracingDoer {
 	client := TracingDoer{
-		doer:   doer,
-		config: config,
+		doer:      doer,
+		transport: &Transport{config: config},
 	}
 	return &client
 }
 
 func (td *TracingDoer) Do(req *http.Request) (*http.Response, error) {
-	span, ctx := Start(req.Context(), "http client request")
-	defer span.End()
-
-	logger := slog.Default().WithGroup("client").With(
-		slog.String("method", req.Method),
-		slog.String("url", req.URL.RequestURI()),
-	)
-
-	// add tracing
-	AddTraceIDHeader(ctx, req)
-	AddSpanIDHeader(ctx, req)
-
-	// dump request body if enabled
-	if td.config.WithRequestBody && req.Body != nil {
-		br := newBodyReader(req.Body, RequestBodyMaxSize, td.config.WithRequestBody)
-		req.Body = br
-
-		logger.WithGroup("request").With(
-			slog.Int64("length", req.ContentLength),
-			slog.String("body", br.body.String()),
-		)
-	}
-
-	if td.config.WithRequestHeaders {
-		kv := []any{}
-		for k, v := range req.Header {
-			if _, found := HiddenRequestHeaders[strings.ToLower(k)]; found {
-				continue
-			}
-			kv = append(kv, slog.Any(k, v))
-		}
-		logger = logger.WithGroup("request").With(kv...)
-	}
-
-	if td.config.WithRequestBody || td.config.WithRequestHeaders {
-		logger.DebugContext(ctx, "http client request")
-	}
-
-	// delegate the request
-	res, err := td.doer.Do(req)
+	res, err := td.transport.roundTrip(req, td.doer.Do, callerPtr(2))
 	if err != nil {
 		return nil, NewDoerErr(err)
 	}
 
-	// dump request body if enabled
-	if td.config.WithResponseBody && res.Body != nil {
-		br := newBodyReader(req.Body, ResponseBodyMaxSize, td.config.WithRequestBody)
-		req.Body = br
-
-		logger.WithGroup("response").With(
-			slog.Int64("length", res.ContentLength),
-			slog.String("body", br.body.String()),
-			slog.Int("status", res.StatusCode),
-		)
-	}
-
-	if td.config.WithResponseHeaders {
-		kv := []any{}
-		for k, v := range res.Header {
-			if _, found := HiddenResponseHeaders[strings.ToLower(k)]; found {
-				continue
-			}
-			kv = append(kv, slog.Any(k, v))
-		}
-		logger = logger.WithGroup("response").With(kv...)
-	}
-
-	if td.config.WithResponseBody || td.config.WithResponseHeaders {
-		logger.DebugContext(ctx, "http client response")
-	}
-
 	return res, nil
 }
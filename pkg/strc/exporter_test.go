@@ -4,7 +4,6 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
-	"math/rand"
 	"regexp"
 	"strings"
 	"testing"
@@ -108,7 +107,7 @@ func TestLogExporter(t *testing.T) {
 	}
 
 	for _, tt := range tests {
-		src = rand.NewSource(0)
+		SetIDGenerator(NewSeededIDGenerator(AlphaFormat, 0))
 
 		want := tt.want
 		t.Run(fmt.Sprintf("%v", want), func(t *testing.T) {
@@ -288,7 +287,7 @@ func TestTraceExporterPC(t *testing.T) {
 	sourceRegexp := regexp.MustCompile(`exporter_test.go:\d+`)
 	for _, tt := range tests {
 		// make sure the source for trace/span IDs is deterministic
-		src = rand.NewSource(0)
+		SetIDGenerator(NewSeededIDGenerator(AlphaFormat, 0))
 
 		want := tt.want
 		t.Run(fmt.Sprintf("%v", tt.name), func(t *testing.T) {
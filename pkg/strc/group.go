@@ -0,0 +1,121 @@
+package strc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"runtime"
+	"sync"
+)
+
+// ErrPanic is wrapped by errors of goroutines which panicked, see Go and Group.
+var ErrPanic = errors.New("panic")
+
+// Go runs the function in a new goroutine with a new child span of the span in the context.
+// The function receives the context of the child span. A returned error or a recovered panic
+// ends the span with error level, panics are logged with "stack" argument.
+//
+//	strc.Go(ctx, "upload", func(ctx context.Context) error {
+//		return upload(ctx, image)
+//	})
+func Go(ctx context.Context, name string, fn func(context.Context) error) {
+	tracer.Load().goSpan(ctx, name, fn, callerPtr(2))
+}
+
+// Go runs the function in a new goroutine, see strc.Go for more information.
+func (t *Tracer) Go(ctx context.Context, name string, fn func(context.Context) error) {
+	t.goSpan(ctx, name, fn, callerPtr(2))
+}
+
+// goSpan starts the span with the source of the Go caller and runs the function
+func (t *Tracer) goSpan(ctx context.Context, name string, fn func(context.Context) error, source string) {
+	span, ctx := t.Start(ctx, name, withSource(source))
+	span.detach()
+	go func() {
+		_ = runSpan(ctx, span, fn)
+	}()
+}
+
+// Group is a collection of goroutines working on subtasks of the same span, similarly to
+// errgroup. Each goroutine runs in its own child span and panics are recovered. Unlike
+// errgroup, the context is not cancelled when a goroutine fails and all errors are returned.
+type Group struct {
+	ctx    context.Context
+	tracer *Tracer
+	wg     sync.WaitGroup
+
+	mu   sync.Mutex
+	errs []error
+}
+
+// NewGroup returns a new group, spans of the goroutines are children of the span in the
+// context.
+//
+//	g := strc.NewGroup(ctx)
+//	for _, image := range images {
+//		g.Go("build "+image.Name, func(ctx context.Context) error {
+//			return build(ctx, image)
+//		})
+//	}
+//	err := g.Wait()
+func NewGroup(ctx context.Context) *Group {
+	return tracer.Load().NewGroup(ctx)
+}
+
+// NewGroup returns a new group, see strc.NewGroup for more information.
+func (t *Tracer) NewGroup(ctx context.Context) *Group {
+	return &Group{ctx: ctx, tracer: t}
+}
+
+// Go runs the function in a new goroutine with a new child span, see strc.Go.
+func (g *Group) Go(name string, fn func(context.Context) error) {
+	span, ctx := g.tracer.Start(g.ctx, name, withSource(callerPtr(2)))
+	span.detach()
+
+	g.wg.Add(1)
+	go func() {
+		defer g.wg.Done()
+
+		if err := runSpan(ctx, span, fn); err != nil {
+			g.mu.Lock()
+			g.errs = append(g.errs, err)
+			g.mu.Unlock()
+		}
+	}()
+}
+
+// Wait blocks until all goroutines finish and returns their errors joined, or nil.
+func (g *Group) Wait() error {
+	g.wg.Wait()
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	return errors.Join(g.errs...)
+}
+
+// Detach returns a new context which keeps the trace, span and all other values but it is
+// never cancelled and has no deadline. Use it for background work which outlives a request.
+func Detach(ctx context.Context) context.Context {
+	return context.WithoutCancel(ctx)
+}
+
+// runSpan runs the function, recovers a panic and ends the span
+func runSpan(ctx context.Context, span *Span, fn func(context.Context) error) (err error) {
+	span.attach()
+
+	defer func() {
+		if r := recover(); r != nil {
+			buf := make([]byte, 2048)
+			n := runtime.Stack(buf, false)
+			buf = buf[:n]
+
+			err = fmt.Errorf("%w: %v", ErrPanic, r)
+			span.SetAttributes(slog.String("stack", string(buf)))
+		}
+		span.EndWithError(err)
+	}()
+
+	return fn(ctx)
+}
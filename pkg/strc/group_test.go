@@ -0,0 +1,132 @@
+package strc
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/osbuild/logging/pkg/collect"
+)
+
+func TestGroup(t *testing.T) {
+	ch := collect.NewTestHandler(slog.LevelDebug, false, false, false)
+	tracer := NewTracer(slog.New(NewMultiHandler(ch)))
+
+	span, ctx := tracer.Start(context.Background(), "parent")
+	errFailed := errors.New("failed")
+
+	g := tracer.NewGroup(ctx)
+	g.Go("ok", func(ctx context.Context) error {
+		if SpanIDFromContext(ctx).ParentID() != SpanIDFromContext(span.ctx).ID() {
+			t.Errorf("unexpected parent %s", SpanIDFromContext(ctx))
+		}
+		return nil
+	})
+	g.Go("error", func(ctx context.Context) error {
+		return errFailed
+	})
+	g.Go("panic", func(ctx context.Context) error {
+		panic("boom")
+	})
+	err := g.Wait()
+	span.End()
+
+	if !errors.Is(err, errFailed) || !errors.Is(err, ErrPanic) || !strings.Contains(err.Error(), "boom") {
+		t.Errorf("unexpected error %v", err)
+	}
+
+	for _, r := range ch.All() {
+		sg := r["span"].(map[string]any)
+		if source, _ := sg["source"].(string); sg["name"] != "parent" && !strings.Contains(source, "group_test.go") {
+			t.Errorf("span %s has unexpected source %q", sg["name"], source)
+		}
+		if _, ok := sg["dur"]; !ok || sg["name"] == "parent" {
+			continue
+		}
+
+		if sg["parent"] != SpanIDFromContext(ctx).ID() {
+			t.Errorf("span %s is not a child: %v", sg["name"], r)
+		}
+		switch sg["name"] {
+		case "ok":
+			if sg["status"] != nil {
+				t.Errorf("unexpected status: %v", r)
+			}
+		case "error":
+			if sg["status"] != "error" || sg["error"] != "failed" {
+				t.Errorf("unexpected status: %v", r)
+			}
+		case "panic":
+			if sg["status"] != "error" || !strings.Contains(sg["stack"].(string), "goroutine") {
+				t.Errorf("unexpected status: %v", r)
+			}
+		}
+	}
+}
+
+func TestGo(t *testing.T) {
+	tracer := NewTracer(slog.New(&NoopHandler{}))
+	span, ctx := tracer.Start(context.Background(), "parent")
+	defer span.End()
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	tracer.Go(ctx, "child", func(cctx context.Context) error {
+		defer wg.Done()
+		if TraceIDFromContext(cctx) != span.TraceID() || SpanIDFromContext(cctx).ParentID() != SpanIDFromContext(ctx).ID() {
+			t.Errorf("unexpected child context %s %s", TraceIDFromContext(cctx), SpanIDFromContext(cctx))
+		}
+		panic("recovered")
+	})
+	wg.Wait()
+}
+
+func TestGoSource(t *testing.T) {
+	ch := collect.NewTestHandler(slog.LevelDebug, false, false, false)
+	SetLogger(slog.New(NewMultiHandler(ch)))
+	defer SetNoopLogger()
+
+	done := make(chan struct{})
+	Go(context.Background(), "child", func(ctx context.Context) error {
+		defer close(done)
+		return nil
+	})
+	<-done
+	// the span ends after the function returns
+	deadline := time.Now().Add(5 * time.Second)
+	for ch.Count() < 2 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+
+	if ch.Count() != 2 {
+		t.Fatalf("unexpected records: %v", ch.All())
+	}
+	for _, r := range ch.All() {
+		if source, _ := r["span"].(map[string]any)["source"].(string); !strings.Contains(source, "group_test.go") {
+			t.Errorf("unexpected source %q", source)
+		}
+	}
+}
+
+func TestDetach(t *testing.T) {
+	span, ctx := NewTracer(slog.New(&NoopHandler{})).Start(context.Background(), "request")
+	defer span.End()
+
+	ctx, cancel := context.WithTimeout(ctx, time.Millisecond)
+	cancel()
+
+	detached := Detach(ctx)
+	if detached.Err() != nil {
+		t.Error("detached context is cancelled")
+	}
+	if _, ok := detached.Deadline(); ok {
+		t.Error("detached context has a deadline")
+	}
+	if TraceIDFromContext(detached) != span.TraceID() || SpanFromContext(detached) != span {
+		t.Error("detached context lost the trace")
+	}
+}
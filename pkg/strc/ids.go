@@ -0,0 +1,159 @@
+package strc
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"math/rand"
+	randv2 "math/rand/v2"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// IDGenerator generates trace and span IDs. Implementations must be safe for concurrent use.
+type IDGenerator interface {
+	// NewTraceID returns a new trace ID.
+	NewTraceID() TraceID
+
+	// NewSpanID returns a new span ID with the ID part of the parent span ID as its parent.
+	NewSpanID(parent SpanID) SpanID
+}
+
+// IDFormat is the format of generated trace and span IDs.
+type IDFormat int
+
+const (
+	// AlphaFormat generates 15 characters long trace IDs and 7 characters long span IDs
+	// from upper and lower case letters, for example "LOlIxiHprrrvHqD" and "yuufaHI".
+	AlphaFormat IDFormat = iota
+
+	// HexFormat generates W3C compatible 128-bit trace IDs and 64-bit span IDs in lower
+	// case hex encoding, for example "4bf92f3577b34da6a3ce929d0e0e4736" and "00f067aa0ba902b7".
+	HexFormat
+)
+
+type idGeneratorHolder struct {
+	IDGenerator
+}
+
+var idGenerator atomic.Pointer[idGeneratorHolder]
+
+func init() {
+	SetIDGenerator(NewIDGenerator(AlphaFormat))
+}
+
+// SetIDGenerator sets the package ID generator used by NewTraceID, NewSpanID and tracers
+// which were created without an ID generator.
+func SetIDGenerator(g IDGenerator) {
+	idGenerator.Store(&idGeneratorHolder{g})
+}
+
+// WithIDGenerator is a TracerOption that sets an ID generator for the tracer.
+func WithIDGenerator(g IDGenerator) TracerOption {
+	return func(t *Tracer) {
+		t.ids = g
+	}
+}
+
+// randomIDGenerator generates IDs from the goroutine-safe and unpredictable math/rand/v2
+// top-level source.
+type randomIDGenerator struct {
+	format IDFormat
+}
+
+// NewIDGenerator returns an ID generator which is safe for concurrent use. IDs are
+// generated from the runtime random source which is randomly seeded and unpredictable.
+// This is the default generator.
+func NewIDGenerator(format IDFormat) IDGenerator {
+	return &randomIDGenerator{format: format}
+}
+
+func (g *randomIDGenerator) NewTraceID() TraceID {
+	return TraceID(newID(g.format, traceLength, w3cTraceLength, randv2.Int64, randv2.Uint64))
+}
+
+func (g *randomIDGenerator) NewSpanID(parent SpanID) SpanID {
+	return SpanID(parent.ID() + "." + newID(g.format, spanLength, w3cSpanLength, randv2.Int64, randv2.Uint64))
+}
+
+// seededIDGenerator generates deterministic IDs from a seeded source, access is serialized.
+type seededIDGenerator struct {
+	format IDFormat
+	src    rand.Source
+	mu     sync.Mutex
+}
+
+// NewSeededIDGenerator returns a deterministic ID generator. It is safe for concurrent use
+// but the sequence of IDs is only deterministic when used from a single goroutine. Only
+// intended for tests.
+func NewSeededIDGenerator(format IDFormat, seed int64) IDGenerator {
+	return &seededIDGenerator{format: format, src: rand.NewSource(seed)}
+}
+
+func (g *seededIDGenerator) NewTraceID() TraceID {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	return TraceID(newID(g.format, traceLength, w3cTraceLength, g.src.Int63, g.uint64))
+}
+
+func (g *seededIDGenerator) NewSpanID(parent SpanID) SpanID {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	return SpanID(parent.ID() + "." + newID(g.format, spanLength, w3cSpanLength, g.src.Int63, g.uint64))
+}
+
+func (g *seededIDGenerator) uint64() uint64 {
+	return uint64(g.src.Int63())<<1 ^ uint64(g.src.Int63())
+}
+
+func newID(format IDFormat, alphaLength, hexLength int, int63 func() int64, uint64 func() uint64) string {
+	if format == HexFormat {
+		return randHex(hexLength, uint64)
+	}
+
+	return randString(alphaLength, int63)
+}
+
+const (
+	letterBytes   = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ"
+	letterIdxBits = 6                    // 6 bits to represent a letter index
+	letterIdxMask = 1<<letterIdxBits - 1 // All 1-bits, as many as letterIdxBits
+	letterIdxMax  = 63 / letterIdxBits   // # of letter indices fitting in 63 bits
+)
+
+func randString(n int, int63 func() int64) string {
+	// decently fast random string generator
+	sb := strings.Builder{}
+	sb.Grow(n)
+	for i, cache, remain := n-1, int63(), letterIdxMax; i >= 0; {
+		if remain == 0 {
+			cache, remain = int63(), letterIdxMax
+		}
+		if idx := int(cache & letterIdxMask); idx < len(letterBytes) {
+			sb.WriteByte(letterBytes[idx])
+			i--
+		}
+		cache >>= letterIdxBits
+		remain--
+	}
+
+	return sb.String()
+}
+
+func randHex(n int, uint64 func() uint64) string {
+	b := make([]byte, n/2)
+	for {
+		for i := 0; i < len(b); i += 8 {
+			binary.BigEndian.PutUint64(b[i:], uint64())
+		}
+
+		// all zeroes is not a valid W3C ID
+		for _, c := range b {
+			if c != 0 {
+				return hex.EncodeToString(b)
+			}
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package strc
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"testing"
+)
+
+func TestIDGeneratorConcurrent(t *testing.T) {
+	for _, format := range []IDFormat{AlphaFormat, HexFormat} {
+		g := NewIDGenerator(format)
+
+		var mu sync.Mutex
+		seen := make(map[TraceID]struct{})
+		wg := sync.WaitGroup{}
+		for i := 0; i < 8; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for j := 0; j < 1000; j++ {
+					tid := g.NewTraceID()
+					mu.Lock()
+					seen[tid] = struct{}{}
+					mu.Unlock()
+				}
+			}()
+		}
+		wg.Wait()
+
+		if len(seen) != 8*1000 {
+			t.Errorf("format %d: generated %d unique IDs, want %d", format, len(seen), 8*1000)
+		}
+	}
+}
+
+func TestIDGeneratorHexFormat(t *testing.T) {
+	g := NewSeededIDGenerator(HexFormat, 0)
+
+	tid := g.NewTraceID()
+	if len(tid) != w3cTraceLength || tid.W3C() != string(tid) {
+		t.Errorf("unexpected hex trace ID %s", tid)
+	}
+
+	sid := g.NewSpanID(EmptySpanID)
+	if sid.ParentID() != EmptySpanID.ID() || len(sid.ID()) != w3cSpanLength || sid.W3C() != sid.ID() {
+		t.Errorf("unexpected hex span ID %s", sid)
+	}
+
+	child := g.NewSpanID(sid)
+	if child.ParentID() != sid.ID() || len(child.ID()) != w3cSpanLength {
+		t.Errorf("unexpected hex child span ID %s", child)
+	}
+
+	if SpanIDFromW3C(child.W3C()).ID() != child.ID() {
+		t.Errorf("hex span ID %s does not round-trip", child)
+	}
+}
+
+func TestSeededIDGeneratorDeterministic(t *testing.T) {
+	for _, format := range []IDFormat{AlphaFormat, HexFormat} {
+		g1 := NewSeededIDGenerator(format, 42)
+		g2 := NewSeededIDGenerator(format, 42)
+
+		for i := 0; i < 10; i++ {
+			if a, b := g1.NewTraceID(), g2.NewTraceID(); a != b {
+				t.Errorf("format %d: trace IDs differ %s != %s", format, a, b)
+			}
+			if a, b := g1.NewSpanID(EmptySpanID), g2.NewSpanID(EmptySpanID); a != b {
+				t.Errorf("format %d: span IDs differ %s != %s", format, a, b)
+			}
+		}
+	}
+}
+
+func TestTracerIDGenerator(t *testing.T) {
+	tracer := NewTracer(slog.New(&NoopHandler{}), WithIDGenerator(NewSeededIDGenerator(HexFormat, 0)))
+
+	span, ctx := tracer.Start(context.Background(), "hex")
+	defer span.End()
+
+	if len(span.TraceID()) != w3cTraceLength {
+		t.Errorf("unexpected trace ID %s", span.TraceID())
+	}
+
+	sid := SpanIDFromContext(ctx)
+	if sid.ParentID() != EmptySpanID.ID() || len(sid.ID()) != w3cSpanLength {
+		t.Errorf("unexpected span ID %s", sid)
+	}
+}
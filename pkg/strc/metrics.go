@@ -0,0 +1,177 @@
+package strc
+
+import (
+	"bufio"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DefaultDurationBuckets are the default histogram buckets in seconds, same as the Prometheus
+// client library default buckets.
+var DefaultDurationBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+const (
+	// DefaultDurationMetricName is the default name of the span duration histogram.
+	DefaultDurationMetricName = "strc_span_duration_seconds"
+
+	// DefaultMaxSeries is the default maximum number of span name and status combinations.
+	DefaultMaxSeries = 1000
+
+	// OverflowSpanName is the name label value used for spans over the MaxSeries limit.
+	OverflowSpanName = "other"
+)
+
+// DurationMetricsConfig is the configuration for DurationMetrics.
+type DurationMetricsConfig struct {
+	// Name is the metric name. Defaults to DefaultDurationMetricName.
+	Name string
+
+	// Buckets are upper bounds of histogram buckets in seconds in increasing order. Defaults
+	// to DefaultDurationBuckets.
+	Buckets []float64
+
+	// MaxSeries is the maximum number of span name and status combinations. Spans with new
+	// names over the limit are counted with OverflowSpanName name, room for one overflow series
+	// per status is reserved within the limit. Values under 3 are raised to 3. Defaults to
+	// DefaultMaxSeries.
+	MaxSeries int
+}
+
+// DurationMetrics is a SpanProcessor which keeps histograms of span durations by span name and
+// status. It is an http.Handler which serves them in Prometheus text exposition format, no
+// client library is needed.
+//
+//	metrics := strc.NewDurationMetrics(strc.DurationMetricsConfig{})
+//	strc.SetLogger(logger, strc.WithSpanProcessor(metrics))
+//	http.Handle("/metrics", metrics)
+type DurationMetrics struct {
+	config DurationMetricsConfig
+
+	mu       sync.Mutex
+	series   map[seriesKey]*histogram
+	overflow int
+}
+
+var _ SpanProcessor = (*DurationMetrics)(nil)
+var _ http.Handler = (*DurationMetrics)(nil)
+
+// overflowSeries is the number of overflow series, one per status
+const overflowSeries = 3
+
+type seriesKey struct {
+	name   string
+	status StatusCode
+}
+
+type histogram struct {
+	counts []uint64 // per bucket, not cumulative, last is +Inf
+	sum    float64
+	count  uint64
+}
+
+// NewDurationMetrics creates new span duration metrics.
+func NewDurationMetrics(config DurationMetricsConfig) *DurationMetrics {
+	if config.Name == "" {
+		config.Name = DefaultDurationMetricName
+	}
+	if len(config.Buckets) == 0 {
+		config.Buckets = DefaultDurationBuckets
+	}
+	if config.MaxSeries <= 0 {
+		config.MaxSeries = DefaultMaxSeries
+	}
+	if config.MaxSeries < overflowSeries {
+		config.MaxSeries = overflowSeries
+	}
+
+	return &DurationMetrics{
+		config: config,
+		series: make(map[seriesKey]*histogram),
+	}
+}
+
+// OnEnd observes duration of the finished span.
+func (m *DurationMetrics) OnEnd(span SpanData) {
+	seconds := span.End.Sub(span.Start).Seconds()
+	key := seriesKey{name: span.Name, status: span.Status}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	h, ok := m.series[key]
+	if !ok {
+		// overflow series which were not created yet are counted in
+		if len(m.series)+overflowSeries-m.overflow >= m.config.MaxSeries {
+			key.name = OverflowSpanName
+			h = m.series[key]
+			if h == nil {
+				m.overflow++
+			}
+		}
+		if h == nil {
+			h = &histogram{counts: make([]uint64, len(m.config.Buckets)+1)}
+			m.series[key] = h
+		}
+	}
+
+	i, _ := slices.BinarySearch(m.config.Buckets, seconds)
+	h.counts[i]++
+	h.sum += seconds
+	h.count++
+}
+
+// ServeHTTP writes all histograms in Prometheus text exposition format.
+func (m *DurationMetrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+
+	bw := bufio.NewWriter(w)
+	m.write(bw)
+	_ = bw.Flush()
+}
+
+func (m *DurationMetrics) write(w *bufio.Writer) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	name := m.config.Name
+	w.WriteString("# HELP " + name + " Duration of finished spans in seconds.\n")
+	w.WriteString("# TYPE " + name + " histogram\n")
+
+	keys := make([]seriesKey, 0, len(m.series))
+	for k := range m.series {
+		keys = append(keys, k)
+	}
+	slices.SortFunc(keys, func(a, b seriesKey) int {
+		if c := strings.Compare(a.name, b.name); c != 0 {
+			return c
+		}
+		return int(a.status) - int(b.status)
+	})
+
+	for _, k := range keys {
+		h := m.series[k]
+		labels := `name="` + escapeLabel(k.name) + `",status="` + k.status.String() + `"`
+
+		var cumulative uint64
+		for i, le := range m.config.Buckets {
+			cumulative += h.counts[i]
+			w.WriteString(name + "_bucket{" + labels + `,le="` + formatFloat(le) + `"} ` + strconv.FormatUint(cumulative, 10) + "\n")
+		}
+		w.WriteString(name + "_bucket{" + labels + `,le="+Inf"} ` + strconv.FormatUint(h.count, 10) + "\n")
+		w.WriteString(name + "_sum{" + labels + "} " + formatFloat(h.sum) + "\n")
+		w.WriteString(name + "_count{" + labels + "} " + strconv.FormatUint(h.count, 10) + "\n")
+	}
+}
+
+var labelReplacer = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escapeLabel(s string) string {
+	return labelReplacer.Replace(s)
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
@@ -0,0 +1,70 @@
+package strc
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDurationMetrics(t *testing.T) {
+	metrics := NewDurationMetrics(DurationMetricsConfig{Buckets: []float64{0.1, 1}})
+	tracer := NewTracer(slog.New(&NoopHandler{}), WithSpanProcessor(metrics))
+
+	now := time.Now()
+	for _, d := range []time.Duration{50 * time.Millisecond, 100 * time.Millisecond, 500 * time.Millisecond, 2 * time.Second} {
+		span, _ := tracer.Start(context.Background(), "query", WithStartTime(now))
+		span.End("finished", now.Add(d))
+	}
+	span, _ := tracer.Start(context.Background(), `quoted "name"`)
+	span.EndWithError(errors.New("failed"))
+
+	rec := httptest.NewRecorder()
+	metrics.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+
+	if !strings.HasPrefix(rec.Header().Get("Content-Type"), "text/plain; version=0.0.4") {
+		t.Errorf("unexpected content type %s", rec.Header().Get("Content-Type"))
+	}
+
+	body := rec.Body.String()
+	for _, line := range []string{
+		"# TYPE strc_span_duration_seconds histogram\n",
+		`strc_span_duration_seconds_bucket{name="query",status="unset",le="0.1"} 2` + "\n",
+		`strc_span_duration_seconds_bucket{name="query",status="unset",le="1"} 3` + "\n",
+		`strc_span_duration_seconds_bucket{name="query",status="unset",le="+Inf"} 4` + "\n",
+		`strc_span_duration_seconds_sum{name="query",status="unset"} 2.65` + "\n",
+		`strc_span_duration_seconds_count{name="query",status="unset"} 4` + "\n",
+		`strc_span_duration_seconds_count{name="quoted \"name\"",status="error"} 1` + "\n",
+	} {
+		if !strings.Contains(body, line) {
+			t.Errorf("line %q not found in:\n%s", line, body)
+		}
+	}
+}
+
+func TestDurationMetricsMaxSeries(t *testing.T) {
+	metrics := NewDurationMetrics(DurationMetricsConfig{MaxSeries: 5})
+
+	for _, name := range []string{"a", "b", "c", "d", "a"} {
+		metrics.OnEnd(SpanData{Name: name})
+	}
+	metrics.OnEnd(SpanData{Name: "e", Status: StatusOK})
+	metrics.OnEnd(SpanData{Name: "e", Status: StatusError})
+	if len(metrics.series) != 5 {
+		t.Errorf("expected 5 series, got %d", len(metrics.series))
+	}
+
+	rec := httptest.NewRecorder()
+	metrics.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+
+	body := rec.Body.String()
+	if !strings.Contains(body, `_count{name="a",status="unset"} 2`) || !strings.Contains(body, `_count{name="other",status="unset"} 2`) {
+		t.Errorf("unexpected output:\n%s", body)
+	}
+	if strings.Contains(body, `name="c"`) {
+		t.Errorf("series over the limit:\n%s", body)
+	}
+}
@@ -2,6 +2,7 @@ package strc
 
 import (
 	"log/slog"
+	"net/http"
 )
 
 // This code is coming from https://github.com/samber/slog-http
@@ -35,4 +36,42 @@ type MiddlewareConfig struct {
 
 	// ServerErrorLevel is the log level for requests with server errors (5xx). Defaults to Error.
 	ServerErrorLevel slog.Level
+
+	// SkipSpan is an optional function which returns true for requests which are not traced
+	// with a server span by the trace extractor, for example health and metrics endpoints.
+	// See SkipPaths.
+	SkipSpan func(r *http.Request) bool
+
+	// HeaderPrecedence selects the header family used by the trace extractor when a request
+	// carries both X-Strc and W3C headers. Defaults to PreferStrc.
+	HeaderPrecedence Precedence
+
+	// WithRequestBody captures the request body up to RequestBodyMaxSize by the request logger.
+	WithRequestBody bool
+
+	// WithResponseBody captures the response body up to ResponseBodyMaxSize by the request logger.
+	WithResponseBody bool
+
+	// WithRequestHeaders captures request headers except HiddenRequestHeaders by the request
+	// logger.
+	WithRequestHeaders bool
+
+	// WithResponseHeaders captures response headers except HiddenResponseHeaders by the request
+	// logger.
+	WithResponseHeaders bool
+
+	// BodyContentTypes limits body capture to requests and responses with content types
+	// starting with one of the prefixes, for example "application/json". Bodies of all content
+	// types are captured when empty.
+	BodyContentTypes []string
+
+	// CaptureFilter is an optional function which returns true for requests with capture
+	// enabled, for example for some routes only. The route is the matched route template,
+	// c.Path() for Echo or the ServeMux pattern for net/http, and it is empty when not known.
+	// Capture is enabled for all requests when nil.
+	CaptureFilter func(r *http.Request, route string) bool
+
+	// CaptureOnlyErrors logs captured bodies and headers only for responses with client or
+	// server errors (4xx and 5xx).
+	CaptureOnlyErrors bool
 }
@@ -0,0 +1,108 @@
+package strc
+
+import (
+	"bytes"
+	"log/slog"
+	"net/http"
+	"strings"
+)
+
+// requestCapture captures bodies and headers of a request and its response for the request
+// logger, it is shared by Echo and net/http middlewares.
+type requestCapture struct {
+	config    MiddlewareConfig
+	header    http.Header
+	resHeader http.Header
+	body      *bodyReader
+	writer    *responseWriter
+	enabled   bool
+}
+
+// startCapture wraps the request body and, for response body capture, the response writer when
+// capture is enabled for the request. The returned writer must be used for the response, it is
+// the writer passed in when the response body is not captured.
+func startCapture(config MiddlewareConfig, r *http.Request, route string, w http.ResponseWriter) (*requestCapture, http.ResponseWriter) {
+	c := &requestCapture{config: config, header: r.Header, resHeader: w.Header()}
+	if !config.WithRequestBody && !config.WithResponseBody && !config.WithRequestHeaders && !config.WithResponseHeaders {
+		return c, w
+	}
+	if config.CaptureFilter != nil && !config.CaptureFilter(r, route) {
+		return c, w
+	}
+	c.enabled = true
+
+	if config.WithRequestBody && r.Body != nil && r.Body != http.NoBody && c.contentTypeAllowed(r.Header) {
+		c.body = newBodyReader(r.Body, RequestBodyMaxSize, true)
+		r.Body = c.body
+	}
+	if config.WithResponseBody {
+		rw, ok := w.(*responseWriter)
+		if !ok {
+			rw = newResponseWriter(w)
+		}
+		rw.captureBody(ResponseBodyMaxSize, c.contentTypeAllowed)
+		c.writer = rw
+		return c, rw
+	}
+
+	return c, w
+}
+
+func (c *requestCapture) contentTypeAllowed(header http.Header) bool {
+	if len(c.config.BodyContentTypes) == 0 {
+		return true
+	}
+
+	ct := header.Get("Content-Type")
+	for _, prefix := range c.config.BodyContentTypes {
+		if strings.HasPrefix(ct, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// attrs returns captured attributes for the request and the response groups.
+func (c *requestCapture) attrs(status int) ([]slog.Attr, []slog.Attr) {
+	if !c.enabled || (c.config.CaptureOnlyErrors && status < http.StatusBadRequest) {
+		return nil, nil
+	}
+
+	var req, res []slog.Attr
+	if c.body != nil && c.body.body.Len() > 0 {
+		req = append(req, slog.String("body", c.body.body.String()))
+	}
+	if c.config.WithRequestHeaders {
+		req = append(req, headersAttr(c.header, HiddenRequestHeaders))
+	}
+	if c.writer != nil && c.writer.body != nil && c.writer.body.Len() > 0 {
+		res = append(res, slog.String("body", c.writer.body.String()))
+	}
+	if c.config.WithResponseHeaders {
+		res = append(res, headersAttr(c.resHeader, HiddenResponseHeaders))
+	}
+
+	return req, res
+}
+
+// headersAttr returns "headers" group with all headers except hidden ones.
+func headersAttr(header http.Header, hidden map[string]struct{}) slog.Attr {
+	attrs := make([]slog.Attr, 0, len(header))
+	for k, v := range header {
+		if _, found := hidden[strings.ToLower(k)]; found {
+			continue
+		}
+		attrs = append(attrs, slog.Any(k, v))
+	}
+
+	return slog.Attr{Key: "headers", Value: slog.GroupValue(attrs...)}
+}
+
+// captureBody enables response body capture up to maxSize bytes, allowed is checked on the
+// first write when response headers are known.
+func (w *responseWriter) captureBody(maxSize int, allowed func(http.Header) bool) {
+	w.maxSize = maxSize
+	w.allowed = allowed
+	w.body = &bytes.Buffer{}
+}
@@ -3,6 +3,7 @@ package strc
 import (
 	"context"
 	"log/slog"
+	"net/http"
 
 	"github.com/labstack/echo/v4"
 )
@@ -18,17 +19,32 @@ type HeaderField struct {
 func EchoHeadersExtractor(pairs []HeaderField) echo.MiddlewareFunc {
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(c echo.Context) error {
-			for _, p := range pairs {
-				if value := c.Request().Header.Get(p.HeaderName); value != "" {
-					c.SetRequest(c.Request().WithContext(context.WithValue(c.Request().Context(), p, value)))
-				}
-			}
-
+			c.SetRequest(headersToContext(c.Request(), pairs))
 			return next(c)
 		}
 	}
 }
 
+// HeadersExtractor is the net/http variant of EchoHeadersExtractor.
+func HeadersExtractor(pairs []HeaderField) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			next.ServeHTTP(w, headersToContext(r, pairs))
+		})
+	}
+}
+
+// headersToContext stores values of headers in the request context.
+func headersToContext(r *http.Request, pairs []HeaderField) *http.Request {
+	for _, p := range pairs {
+		if value := r.Header.Get(p.HeaderName); value != "" {
+			r = r.WithContext(context.WithValue(r.Context(), p, value))
+		}
+	}
+
+	return r
+}
+
 // HeadersCallback is a slog callback that extracts values from the
 // context and adds them to the attributes.
 func HeadersCallback(pairs []HeaderField) MultiCallback {
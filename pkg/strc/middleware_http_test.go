@@ -0,0 +1,251 @@
+package strc_test
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/osbuild/logging/pkg/collect"
+	"github.com/osbuild/logging/pkg/strc"
+)
+
+func chain(h http.Handler, middlewares ...func(http.Handler) http.Handler) http.Handler {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		h = middlewares[i](h)
+	}
+	return h
+}
+
+// withoutTime removes attributes which differ between requests
+func withoutTime(m map[string]any) map[string]any {
+	delete(m, "time")
+	delete(m, "latency")
+	delete(m, strc.TraceIDKey)
+	delete(m, strc.SpanIDKey)
+	return m
+}
+
+func TestRequestLoggerParity(t *testing.T) {
+	for _, status := range []int{http.StatusOK, http.StatusNotFound, http.StatusServiceUnavailable} {
+		echoHandler := collect.NewTestHandler(slog.LevelDebug, false, false, true)
+		e := echo.New()
+		e.Use(strc.EchoTraceExtractor(), strc.EchoRequestLogger(slog.New(echoHandler), strc.MiddlewareConfig{
+			ClientErrorLevel: slog.LevelWarn,
+			ServerErrorLevel: slog.LevelError,
+		}))
+		e.GET("/x", func(c echo.Context) error {
+			return c.String(status, "body")
+		})
+
+		httpHandler := collect.NewTestHandler(slog.LevelDebug, false, false, true)
+		h := chain(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(status)
+			_, _ = w.Write([]byte("body"))
+		}), strc.TraceExtractor(), strc.RequestLogger(slog.New(httpHandler), strc.MiddlewareConfig{
+			ClientErrorLevel: slog.LevelWarn,
+			ServerErrorLevel: slog.LevelError,
+		}))
+
+		echoRec := httptest.NewRecorder()
+		e.ServeHTTP(echoRec, httptest.NewRequest(http.MethodGet, "http://example.com/x", nil))
+		httpRec := httptest.NewRecorder()
+		h.ServeHTTP(httpRec, httptest.NewRequest(http.MethodGet, "http://example.com/x", nil))
+
+		assert.Equal(t, withoutTime(echoHandler.Last()), withoutTime(httpHandler.Last()))
+		assert.True(t, httpHandler.Contains(int64(status), "response", "status"))
+		assert.True(t, httpHandler.Contains(int64(4), "response", "length"))
+		assert.NotEmpty(t, echoRec.Result().Header.Get(strc.TraceHTTPHeaderName))
+		assert.NotEmpty(t, httpRec.Result().Header.Get(strc.TraceHTTPHeaderName))
+	}
+}
+
+func TestTraceExtractor(t *testing.T) {
+	var traceID strc.TraceID
+	h := chain(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceID = strc.TraceIDFromContext(r.Context())
+		_, _ = w.Write([]byte("OK"))
+	}), strc.TraceExtractor())
+
+	req := httptest.NewRequest(http.MethodGet, "http://example.com/ok", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	assert.Equal(t, strc.TraceID("4bf92f3577b34da6a3ce929d0e0e4736"), traceID)
+	assert.Equal(t, traceID.String(), rec.Result().Header.Get(strc.TraceHTTPHeaderName))
+}
+
+func TestTraceExtractorSpan(t *testing.T) {
+	logHandler := collect.NewTestHandler(slog.LevelDebug, false, false, false)
+	strc.SetLogger(slog.New(strc.NewMultiHandler(logHandler)))
+	defer strc.SetNoopLogger()
+
+	h := chain(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}), strc.TraceExtractor())
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "http://example.com/ok", nil))
+
+	assert.Equal(t, 2, logHandler.CountWith("span", "kind"))
+	assert.Equal(t, 0, logHandler.CountWith("span", "source"), "middleware source is not useful")
+}
+
+func TestTraceExtractorUnsampled(t *testing.T) {
+	logHandler := collect.NewTestHandler(slog.LevelDebug, false, false, false)
+	strc.SetLogger(slog.New(strc.NewMultiHandler(logHandler)))
+	defer strc.SetNoopLogger()
+
+	var sampled bool
+	var traceparent string
+	h := chain(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sampled = strc.IsSampled(r.Context())
+		out, _ := http.NewRequestWithContext(r.Context(), http.MethodGet, "http://example.com/next", nil)
+		strc.AddTraceHeaders(r.Context(), out)
+		traceparent = out.Header.Get("traceparent")
+	}), strc.TraceExtractor())
+
+	req := httptest.NewRequest(http.MethodGet, "http://example.com/ok", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00")
+	h.ServeHTTP(httptest.NewRecorder(), req)
+
+	assert.False(t, sampled)
+	assert.True(t, strings.HasSuffix(traceparent, "-00"), traceparent)
+	assert.Equal(t, 0, logHandler.CountWith("span", "name"))
+}
+
+func TestTraceExtractorHeaderPrecedence(t *testing.T) {
+	var traceID strc.TraceID
+	h := chain(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceID = strc.TraceIDFromContext(r.Context())
+	}), strc.TraceExtractorWithConfig(strc.MiddlewareConfig{HeaderPrecedence: strc.PreferW3C}))
+
+	req := httptest.NewRequest(http.MethodGet, "http://example.com/ok", nil)
+	req.Header.Set(strc.TraceHTTPHeaderName, "1zapXiHprrrvHqD")
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	h.ServeHTTP(httptest.NewRecorder(), req)
+
+	assert.Equal(t, strc.TraceID("4bf92f3577b34da6a3ce929d0e0e4736"), traceID)
+}
+
+func TestHeadersExtractorAndContextSetLogger(t *testing.T) {
+	logHandler := collect.NewTestHandler(slog.LevelDebug, false, false, false)
+	pairs := []strc.HeaderField{{HeaderName: "X-Request-Id", FieldName: "request_id"}}
+	logger := slog.New(strc.NewMultiHandlerCustom(nil, strc.HeadersCallback(pairs), logHandler))
+
+	h := chain(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		strc.LoggerFromContext(r.Context()).DebugContext(r.Context(), "handler")
+	}), strc.TraceExtractor(), strc.HeadersExtractor(pairs), strc.ContextSetLogger(logger))
+
+	req := httptest.NewRequest(http.MethodGet, "http://example.com/x", nil)
+	req.Header.Set("X-Request-Id", "abc")
+	req.Header.Set(strc.TraceHTTPHeaderName, "1zapXiHprrrvHqD")
+	h.ServeHTTP(httptest.NewRecorder(), req)
+
+	assert.True(t, logHandler.Contains("handler", slog.MessageKey))
+	assert.True(t, logHandler.Contains("abc", "request_id"))
+	assert.True(t, logHandler.Contains("1zapXiHprrrvHqD", "trace_id"))
+}
+
+func TestRequestLoggerCapture(t *testing.T) {
+	logHandler := collect.NewTestHandler(slog.LevelDebug, false, false, true)
+	h := chain(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Set-Cookie", "secret")
+		_, _ = w.Write(body)
+	}), strc.RequestLogger(slog.New(logHandler), strc.MiddlewareConfig{
+		WithRequestBody:     true,
+		WithResponseBody:    true,
+		WithRequestHeaders:  true,
+		WithResponseHeaders: true,
+		BodyContentTypes:    []string{"application/json"},
+	}))
+
+	req := httptest.NewRequest(http.MethodPost, "http://example.com/x", strings.NewReader(`{"a":1}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "secret")
+	h.ServeHTTP(httptest.NewRecorder(), req)
+
+	assert.True(t, logHandler.Contains(`{"a":1}`, "request", "body"))
+	assert.True(t, logHandler.Contains(`{"a":1}`, "response", "body"))
+	assert.True(t, logHandler.Contains([]string{"application/json"}, "request", "headers", "Content-Type"))
+	assert.True(t, logHandler.Contains([]string{"application/json"}, "response", "headers", "Content-Type"))
+	assert.Equal(t, 0, logHandler.CountWith("request", "headers", "Authorization"))
+	assert.Equal(t, 0, logHandler.CountWith("response", "headers", "Set-Cookie"))
+
+	// body of other content types is not captured
+	logHandler.Reset()
+	req = httptest.NewRequest(http.MethodPost, "http://example.com/x", strings.NewReader("text"))
+	req.Header.Set("Content-Type", "text/plain")
+	h.ServeHTTP(httptest.NewRecorder(), req)
+
+	assert.Equal(t, 0, logHandler.CountWith("request", "body"))
+	assert.Equal(t, 1, logHandler.CountWith("request", "headers"))
+}
+
+func TestRequestLoggerCaptureFilters(t *testing.T) {
+	logHandler := collect.NewTestHandler(slog.LevelDebug, false, false, true)
+	e := echo.New()
+	e.Use(strc.EchoRequestLogger(slog.New(logHandler), strc.MiddlewareConfig{
+		WithResponseBody:  true,
+		CaptureOnlyErrors: true,
+		CaptureFilter: func(r *http.Request, route string) bool {
+			return route != "/skip"
+		},
+	}))
+	e.GET("/:status", func(c echo.Context) error {
+		status, _ := strconv.Atoi(c.Param("status"))
+		return c.String(status, c.Param("status"))
+	})
+	e.GET("/skip", func(c echo.Context) error {
+		return c.String(http.StatusInternalServerError, "skip")
+	})
+
+	for _, path := range []string{"/200", "/404", "/skip"} {
+		e.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "http://example.com"+path, nil))
+	}
+
+	assert.Equal(t, 3, logHandler.Count())
+	assert.Equal(t, 1, logHandler.CountWith("response", "body"))
+	assert.True(t, logHandler.Contains("404", "response", "body"))
+}
+
+func TestEchoRequestLoggerCaptureErrorHandler(t *testing.T) {
+	logHandler := collect.NewTestHandler(slog.LevelDebug, false, false, true)
+	e := echo.New()
+	e.Use(strc.EchoRequestLogger(slog.New(logHandler), strc.MiddlewareConfig{
+		WithResponseBody:  true,
+		CaptureOnlyErrors: true,
+	}))
+	e.GET("/error", func(c echo.Context) error {
+		return echo.NewHTTPError(http.StatusNotFound, "not here")
+	})
+	e.GET("/empty", func(c echo.Context) error {
+		return c.NoContent(http.StatusBadRequest)
+	})
+
+	for _, path := range []string{"/error", "/empty"} {
+		e.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "http://example.com"+path, nil))
+	}
+
+	// the body written by the error handler is captured, empty bodies are omitted
+	assert.Equal(t, 2, logHandler.Count())
+	assert.Equal(t, 1, logHandler.CountWith("response", "body"))
+	assert.True(t, logHandler.Contains(`{"message":"not here"}`+"\n", "response", "body"))
+}
+
+func TestEchoRequestLoggerNoCapture(t *testing.T) {
+	rec := httptest.NewRecorder()
+	e := echo.New()
+	e.Use(strc.EchoRequestLogger(slog.New(&strc.NoopHandler{}), strc.MiddlewareConfig{WithResponseHeaders: true}))
+	e.GET("/", func(c echo.Context) error {
+		assert.Same(t, rec, c.Response().Writer, "response writer must not be wrapped without body capture")
+		return c.NoContent(http.StatusOK)
+	})
+
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "http://example.com/", nil))
+}
@@ -10,19 +10,19 @@ import (
 	echoproxy "github.com/osbuild/logging/pkg/echo"
 )
 
-func slogAttributesFromRequest(r *http.Request) []slog.Attr {
+func slogAttributesFromRequest(r *http.Request, extra ...slog.Attr) []slog.Attr {
 	attrs := []slog.Attr{
 		{
 			Key: "request",
 			Value: slog.GroupValue(
-				[]slog.Attr{
+				append([]slog.Attr{
 					slog.String("method", r.Method),
 					slog.String("host", r.Host),
 					slog.String("path", r.URL.Path),
 					slog.String("user-agent", r.UserAgent()),
 					slog.String("ip", r.RemoteAddr),
 					slog.Int64("length", r.ContentLength),
-				}...,
+				}, extra...)...,
 			),
 		},
 	}
@@ -52,53 +52,97 @@ func statusForLogging(status int, err error) int {
 	return http.StatusInternalServerError
 }
 
-// This generates exactly one log statement per request processed.
+// logRequest logs exactly one record for the processed request, it is shared by Echo and net/http
+// middlewares. When a span is present in the request context, it is marked as failed for server
+// errors (5xx). Captured bodies and headers are added to the request and response groups.
+func logRequest(logger *slog.Logger, config MiddlewareConfig, r *http.Request, start time.Time, status int, size int64, err error, capture *requestCapture) {
+	latency := time.Since(start)
+
+	var attrs []slog.Attr
+	level := config.DefaultLevel
+	if status >= http.StatusInternalServerError {
+		level = config.ServerErrorLevel
+		if err != nil {
+			attrs = append(attrs, slog.String("error", err.Error()))
+		}
+		if span := SpanFromContext(r.Context()); span != nil {
+			span.SetStatus(StatusError, http.StatusText(status))
+			span.RecordError(err)
+		}
+	} else if status >= http.StatusBadRequest && status < http.StatusInternalServerError {
+		level = config.ClientErrorLevel
+	}
+
+	attrs = append(
+		attrs,
+		slog.Time("time", start.UTC()),
+		slog.Duration("latency", latency),
+	)
+	reqAttrs, resAttrs := capture.attrs(status)
+	attrs = append(attrs, slogAttributesFromRequest(r, reqAttrs...)...)
+	attrs = append(
+		attrs,
+		slog.Attr{
+			Key: "response",
+			Value: slog.GroupValue(
+				append([]slog.Attr{
+					slog.Int64("length", size),
+					slog.Int("status", status),
+				}, resAttrs...)...,
+			),
+		},
+	)
+
+	logger.LogAttrs(r.Context(), level, fmt.Sprintf("%d: %s", status, http.StatusText(status)), attrs...)
+}
+
+// This generates exactly one log statement per request processed. When a span is present in
+// the request context, it is marked as failed for server errors (5xx). Request and response
+// bodies and headers can be captured, see MiddlewareConfig.
+//
+// Errors returned by the handler are passed to the echo.HTTPErrorHandler via c.Error before
+// the record is logged, so the logged status and the captured response are what the client
+// receives.
 //
 // Meant to be chained after middlewares that add fields to the request context.
 func EchoRequestLogger(logger *slog.Logger, config MiddlewareConfig) echo.MiddlewareFunc {
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(c echo.Context) error {
 			start := time.Now()
+			capture, w := startCapture(config, c.Request(), c.Path(), c.Response().Writer)
+			c.Response().Writer = w
 			err := next(c)
-			latency := time.Since(start)
-
-			var attrs []slog.Attr
-			status := statusForLogging(c.Response().Status, err)
-			level := config.DefaultLevel
-			if status >= http.StatusInternalServerError {
-				level = config.ServerErrorLevel
-				if err != nil {
-					attrs = append(attrs, slog.String("error", err.Error()))
-				}
-			} else if status >= http.StatusBadRequest && status < http.StatusInternalServerError {
-				level = config.ClientErrorLevel
+			if err != nil {
+				c.Error(err)
 			}
 
-			attrs = append(
-				attrs,
-				slog.Time("time", start.UTC()),
-				slog.Duration("latency", latency),
-			)
-			attrs = append(attrs, slogAttributesFromRequest(c.Request())...)
-			attrs = append(
-				attrs,
-				slog.Attr{
-					Key: "response",
-					Value: slog.GroupValue(
-						[]slog.Attr{
-							slog.Int64("length", c.Response().Size),
-							slog.Int("status", status),
-						}...,
-					),
-				},
-			)
-
-			logger.LogAttrs(c.Request().Context(), level, fmt.Sprintf("%d: %s", status, http.StatusText(status)), attrs...)
+			status := c.Response().Status
+			if !c.Response().Committed {
+				status = statusForLogging(status, err)
+			}
+			logRequest(logger, config, c.Request(), start, status, c.Response().Size, err, capture)
 			return err
 		}
 	}
 }
 
+// RequestLogger is the net/http variant of EchoRequestLogger, it generates exactly one log
+// statement per request processed with the same attributes.
+//
+// Meant to be chained after middlewares that add fields to the request context.
+func RequestLogger(logger *slog.Logger, config MiddlewareConfig) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			rw := newResponseWriter(w)
+			capture, _ := startCapture(config, r, r.Pattern, rw)
+			next.ServeHTTP(rw, r)
+
+			logRequest(logger, config, r, start, rw.status, rw.size, nil, capture)
+		})
+	}
+}
+
 // This sets the logger for each request to the specified logger. Anything processing the
 // cecho.Context can just call echo.Context.Logger() to get the appropriate logger.
 //
@@ -111,3 +155,15 @@ func EchoContextSetLogger(logger *slog.Logger) echo.MiddlewareFunc {
 		}
 	}
 }
+
+// ContextSetLogger is the net/http variant of EchoContextSetLogger. It stores the logger in the
+// request context, handlers can get it via LoggerFromContext.
+//
+// Meant to be chained after middlewares that add fields to the request context.
+func ContextSetLogger(logger *slog.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			next.ServeHTTP(w, r.WithContext(WithLogger(r.Context(), logger)))
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package strc_test
 
 import (
+	"context"
 	"fmt"
 	"log/slog"
 	"net/http"
@@ -60,7 +61,7 @@ func TestEchoRequestLoggerHTTPError400(t *testing.T) {
 	assert.True(t, logHandler.Contains("example.com", "request", "host"))
 	assert.True(t, logHandler.Contains(int64(0), "request", "length"))
 	assert.True(t, logHandler.Contains(int64(400), "response", "status"))
-	assert.True(t, logHandler.Contains(int64(0), "response", "length"))
+	assert.True(t, logHandler.Contains(int64(rec.Body.Len()), "response", "length"))
 	assert.True(t, logHandler.Contains(slog.LevelWarn.String(), "level"))
 }
 
@@ -86,7 +87,7 @@ func TestEchoRequestLoggerHTTPError503(t *testing.T) {
 	assert.True(t, logHandler.Contains("example.com", "request", "host"))
 	assert.True(t, logHandler.Contains(int64(0), "request", "length"))
 	assert.True(t, logHandler.Contains(int64(503), "response", "status"))
-	assert.True(t, logHandler.Contains(int64(0), "response", "length"))
+	assert.True(t, logHandler.Contains(int64(rec.Body.Len()), "response", "length"))
 	assert.True(t, logHandler.Contains(slog.LevelError.String(), "level"))
 }
 
@@ -112,7 +113,7 @@ func TestEchoRequestLoggerError(t *testing.T) {
 	assert.True(t, logHandler.Contains("example.com", "request", "host"))
 	assert.True(t, logHandler.Contains(int64(0), "request", "length"))
 	assert.True(t, logHandler.Contains(int64(500), "response", "status"))
-	assert.True(t, logHandler.Contains(int64(0), "response", "length"))
+	assert.True(t, logHandler.Contains(int64(rec.Body.Len()), "response", "length"))
 	assert.True(t, logHandler.Contains(slog.LevelError.String(), "level"))
 }
 
@@ -146,3 +147,32 @@ func TestEchoContextSetLogger(t *testing.T) {
 		t.Errorf("TraceID not found: %s", logHandler.All())
 	}
 }
+
+func TestEchoRequestLoggerFailsSpan(t *testing.T) {
+	logHandler := collect.NewTestHandler(slog.LevelDebug, false, false, true)
+	logger := slog.New(logHandler)
+
+	var span *strc.Span
+	e := echo.New()
+	e.Use(func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			var ctx context.Context
+			span, ctx = strc.NewTracer(logger).Start(c.Request().Context(), "request")
+			c.SetRequest(c.Request().WithContext(ctx))
+			defer span.End()
+			return next(c)
+		}
+	})
+	e.Use(strc.EchoRequestLogger(logger, strc.MiddlewareConfig{}))
+	e.GET("/error", func(c echo.Context) error {
+		return fmt.Errorf("random error")
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "http://example.com/error", nil)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	assert.Equal(t, strc.StatusError, span.Status())
+	assert.True(t, logHandler.Contains("error", "span", "status"))
+	assert.True(t, logHandler.Contains("Internal Server Error: random error", "span", "error"))
+}
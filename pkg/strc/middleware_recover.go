@@ -8,7 +8,8 @@ import (
 )
 
 // RecoverPanicMiddleware is a middleware that recovers from panics and logs them using slog
-// as errors with status code 500. No body is returned.
+// as errors with status code 500. No body is returned. When a span is present in the request
+// context, it is marked as failed.
 func RecoverPanicMiddleware(logger *slog.Logger) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -18,6 +19,9 @@ func RecoverPanicMiddleware(logger *slog.Logger) func(http.Handler) http.Handler
 					n := runtime.Stack(buf, false)
 					buf = buf[:n]
 					msg := fmt.Sprintf("%v", err)
+					if span := SpanFromContext(r.Context()); span != nil {
+						span.SetStatus(StatusError, "panic: "+msg)
+					}
 
 					logger.ErrorContext(r.Context(), "panic: "+msg,
 						slog.String("error", msg),
@@ -1,42 +1,156 @@
 package strc
 
 import (
+	"log/slog"
 	"net/http"
+	"slices"
+	"strings"
 
 	"github.com/labstack/echo/v4"
 )
 
-func httpRequestWithTracing(r *http.Request) (TraceID, *http.Request) {
-	traceID := TraceIDFromRequest(r)
+func httpRequestWithTracing(r *http.Request, p Precedence) (TraceID, *http.Request) {
+	carrier := HeaderCarrier(r.Header)
+	traceID, spanID := extractIDs(carrier, p)
 	if traceID == EmptyTraceID {
 		traceID = NewTraceID()
 	}
 
 	newCtx := WithTraceID(r.Context(), traceID)
-	r = r.WithContext(newCtx)
-
-	spanID := SpanIDFromRequest(r)
 	if spanID != EmptySpanID {
-		newCtx = WithSpanID(r.Context(), spanID)
-		r = r.WithContext(newCtx)
+		newCtx = WithSpanID(newCtx, spanID)
+	}
+
+	newCtx = extractTraceparent(newCtx, carrier)
+	return traceID, r.WithContext(newCtx)
+}
+
+// traceRequest extracts trace IDs from the request and adds the trace ID response header, it is
+// shared by Echo and net/http middlewares.
+func traceRequest(w http.ResponseWriter, r *http.Request, p Precedence) *http.Request {
+	traceID, r := httpRequestWithTracing(r, p)
+	w.Header().Add(TraceHTTPHeaderName, traceID.String())
+	return r
+}
+
+// serverSpanName returns span name for the request, the method and the matched route template.
+// ServeMux patterns can already contain the method.
+func serverSpanName(method, route string) string {
+	switch {
+	case route == "":
+		return method
+	case strings.HasPrefix(route, method+" "):
+		return route
+	default:
+		return method + " " + route
+	}
+}
+
+// startServerSpan starts a server span for the request unless it is skipped by the config, it is
+// shared by Echo and net/http middlewares. Returns nil span when skipped. The span has no source
+// because the caller is the middleware chain.
+func startServerSpan(r *http.Request, route string, config MiddlewareConfig) (*Span, *http.Request) {
+	if config.SkipSpan != nil && config.SkipSpan(r) {
+		return nil, r
+	}
+
+	args := []any{WithKind(SpanKindServer), withSource(""), slog.String("method", r.Method)}
+	if route != "" {
+		args = append(args, slog.String("route", route))
+	}
+	span, ctx := Start(r.Context(), serverSpanName(r.Method, route), args...)
+
+	return span, r.WithContext(ctx)
+}
+
+// endServerSpan sets response attributes, marks server errors (5xx) as failed and ends the span.
+func endServerSpan(span *Span, status int, size int64, err error) {
+	span.SetAttributes(slog.Int("status_code", status), slog.Int64("response_size", size))
+	if status >= http.StatusInternalServerError {
+		span.SetStatus(StatusError, http.StatusText(status))
+		span.RecordError(err)
+	}
+
+	span.End()
+}
+
+// SkipPaths returns a MiddlewareConfig.SkipSpan function which skips requests with the given URL
+// paths, for example health and metrics endpoints.
+func SkipPaths(paths ...string) func(*http.Request) bool {
+	return func(r *http.Request) bool {
+		return slices.Contains(paths, r.URL.Path)
 	}
-	return traceID, r
 }
 
 // EchoTraceExtractor extracts trace IDs and span IDs from HTTP headers and sets
-// them in the request context.
+// them in the request context. Both X-Strc and W3C traceparent/tracestate headers
+// are supported, see MiddlewareConfig.HeaderPrecedence. The trace ID is returned in the response
+// header.
+//
+// A server span named after the method and the matched route template (c.Path()) is started
+// for each request, see EchoTraceExtractorWithConfig.
 //
 // Meant to be chained before any logging middleware.
 func EchoTraceExtractor() echo.MiddlewareFunc {
+	return EchoTraceExtractorWithConfig(MiddlewareConfig{})
+}
+
+// EchoTraceExtractorWithConfig is EchoTraceExtractor with configuration. The server span has
+// method, route, status code and response size attributes, server errors (5xx) are marked as
+// failed. Use MiddlewareConfig.SkipSpan to skip the span for some requests.
+//
+// Meant to be chained before any logging middleware.
+func EchoTraceExtractorWithConfig(config MiddlewareConfig) echo.MiddlewareFunc {
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(c echo.Context) error {
-			traceID, req := httpRequestWithTracing(c.Request())
+			span, req := startServerSpan(traceRequest(c.Response(), c.Request(), config.HeaderPrecedence), c.Path(), config)
 			c.SetRequest(req)
 
 			err := next(c)
 
-			c.Response().Header().Add(TraceHTTPHeaderName, traceID.String())
+			if span != nil {
+				endServerSpan(span, statusForLogging(c.Response().Status, err), c.Response().Size, err)
+			}
 			return err
 		}
 	}
 }
+
+// TraceExtractor is the net/http variant of EchoTraceExtractor. The route template is
+// available when the next handler is http.ServeMux or when the middleware wraps a single
+// route handler.
+//
+// Meant to be chained before any logging middleware.
+func TraceExtractor() func(http.Handler) http.Handler {
+	return TraceExtractorWithConfig(MiddlewareConfig{})
+}
+
+// TraceExtractorWithConfig is the net/http variant of EchoTraceExtractorWithConfig.
+//
+// Meant to be chained before any logging middleware.
+func TraceExtractorWithConfig(config MiddlewareConfig) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		mux, _ := next.(*http.ServeMux)
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			route := r.Pattern
+			if mux != nil {
+				_, route = mux.Handler(r)
+			}
+
+			span, req := startServerSpan(traceRequest(w, r, config.HeaderPrecedence), route, config)
+			if span == nil {
+				next.ServeHTTP(w, req)
+				return
+			}
+
+			rw := newResponseWriter(w)
+			next.ServeHTTP(rw, req)
+
+			if route == "" && req.Pattern != "" {
+				span.SetAttributes(slog.String("route", req.Pattern))
+			}
+			endServerSpan(span, rw.status, rw.size, nil)
+		})
+	}
+}
@@ -32,3 +32,94 @@ func TestEchoTraceExtractor(t *testing.T) {
 	e.ServeHTTP(rec, req)
 	assert.True(t, logHandler.Contains(traceID, strc.TraceIDKey))
 }
+
+func TestEchoTraceExtractorTraceparent(t *testing.T) {
+	e := echo.New()
+	e.Use(strc.EchoTraceExtractorWithConfig(strc.MiddlewareConfig{
+		SkipSpan: func(*http.Request) bool { return true },
+	}))
+
+	var traceID strc.TraceID
+	var spanID strc.SpanID
+	var flags strc.TraceFlags
+	var state string
+	e.GET("/ok", func(c echo.Context) error {
+		ctx := c.Request().Context()
+		traceID = strc.TraceIDFromContext(ctx)
+		spanID = strc.SpanIDFromContext(ctx)
+		flags = strc.TraceFlagsFromContext(ctx)
+		state = strc.TraceStateFromContext(ctx)
+		return c.String(http.StatusOK, "OK")
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "http://example.com/ok", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00")
+	req.Header.Set("tracestate", "vendor=value")
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	assert.Equal(t, strc.TraceID("4bf92f3577b34da6a3ce929d0e0e4736"), traceID)
+	assert.Equal(t, "00f067aa0ba902b7", spanID.ID())
+	assert.False(t, flags.Sampled())
+	assert.Equal(t, "vendor=value", state)
+	assert.Equal(t, traceID.String(), rec.Header().Get(strc.TraceHTTPHeaderName))
+}
+
+func TestEchoTraceExtractorServerSpan(t *testing.T) {
+	logHandler := collect.NewTestHandler(slog.LevelDebug, false, false, false)
+	strc.SetLogger(slog.New(strc.NewMultiHandler(logHandler)))
+	defer strc.SetNoopLogger()
+
+	e := echo.New()
+	e.Use(strc.EchoTraceExtractorWithConfig(strc.MiddlewareConfig{SkipSpan: strc.SkipPaths("/health")}))
+	e.GET("/users/:id", func(c echo.Context) error {
+		return c.String(http.StatusOK, "user")
+	})
+	e.GET("/fail", func(c echo.Context) error {
+		return echo.NewHTTPError(http.StatusServiceUnavailable)
+	})
+	e.GET("/health", func(c echo.Context) error {
+		return c.NoContent(http.StatusOK)
+	})
+
+	for _, path := range []string{"/users/42", "/fail", "/health"} {
+		e.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "http://example.com"+path, nil))
+	}
+
+	assert.Equal(t, 4, logHandler.Count())
+	assert.True(t, logHandler.Contains("span GET /users/:id started", slog.MessageKey))
+	assert.True(t, logHandler.Contains("server", "span", "kind"))
+	assert.True(t, logHandler.Contains("GET", "span", "method"))
+	assert.True(t, logHandler.Contains("/users/:id", "span", "route"))
+	assert.True(t, logHandler.Contains(int64(200), "span", "status_code"))
+	assert.True(t, logHandler.Contains(int64(4), "span", "response_size"))
+	assert.True(t, logHandler.Contains(int64(503), "span", "status_code"))
+	assert.True(t, logHandler.Contains("error", "span", "status"))
+	assert.False(t, logHandler.Contains("/health", "span", "route"))
+}
+
+func TestTraceExtractorServerSpan(t *testing.T) {
+	logHandler := collect.NewTestHandler(slog.LevelDebug, false, false, false)
+	strc.SetLogger(slog.New(strc.NewMultiHandler(logHandler)))
+	defer strc.SetNoopLogger()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /users/{id}", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("user"))
+	})
+	mux.HandleFunc("/fail", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+	h := strc.TraceExtractor()(mux)
+
+	for _, path := range []string{"/users/42", "/fail"} {
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "http://example.com"+path, nil))
+	}
+
+	assert.True(t, logHandler.Contains("GET /users/{id}", "span", "name"))
+	assert.True(t, logHandler.Contains("GET /users/{id}", "span", "route"))
+	assert.True(t, logHandler.Contains(int64(200), "span", "status_code"))
+	assert.True(t, logHandler.Contains("GET /fail", "span", "name"))
+	assert.True(t, logHandler.Contains(int64(500), "span", "status_code"))
+	assert.True(t, logHandler.Contains("error", "span", "status"))
+}
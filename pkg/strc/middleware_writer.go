@@ -0,0 +1,60 @@
+package strc
+
+import (
+	"bytes"
+	"net/http"
+)
+
+// responseWriter records status code and size of the response for net/http middlewares.
+type responseWriter struct {
+	http.ResponseWriter
+
+	status      int
+	size        int64
+	wroteHeader bool
+
+	// body capture, see captureBody
+	body    *bytes.Buffer
+	maxSize int
+	allowed func(http.Header) bool
+}
+
+func newResponseWriter(w http.ResponseWriter) *responseWriter {
+	return &responseWriter{ResponseWriter: w, status: http.StatusOK}
+}
+
+func (w *responseWriter) WriteHeader(status int) {
+	if !w.wroteHeader {
+		w.status = status
+		w.wroteHeader = true
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *responseWriter) Write(b []byte) (int, error) {
+	w.wroteHeader = true
+	if w.body != nil && w.allowed != nil {
+		if !w.allowed(w.Header()) {
+			w.body = nil
+		}
+		w.allowed = nil
+	}
+
+	n, err := w.ResponseWriter.Write(b)
+	w.size += int64(n)
+	if w.body != nil && w.body.Len() < w.maxSize {
+		w.body.Write(b[:min(n, w.maxSize-w.body.Len())])
+	}
+	return n, err
+}
+
+// Flush implements http.Flusher when the underlying writer supports it.
+func (w *responseWriter) Flush() {
+	w.wroteHeader = true
+	_ = http.NewResponseController(w.ResponseWriter).Flush()
+}
+
+// Unwrap returns the underlying writer for http.ResponseController.
+func (w *responseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
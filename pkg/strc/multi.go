@@ -29,6 +29,7 @@ type MultiHandler struct {
 	handlers    []slog.Handler
 	inSpanGroup bool
 	callback    MultiCallback
+	opts        TracerOptions
 }
 
 type MultiCallback func(context.Context, []slog.Attr) ([]slog.Attr, error)
@@ -43,12 +44,19 @@ func NewMultiHandler(handlers ...slog.Handler) *MultiHandler {
 // to the every record, and a callback that can add dynamic attributes from the context.
 // No custom fields are added to the "span" group.
 func NewMultiHandlerCustom(attrs []slog.Attr, callback MultiCallback, handlers ...slog.Handler) *MultiHandler {
+	return NewMultiHandlerOptions(TracerOptions{}, attrs, callback, handlers...)
+}
+
+// NewMultiHandlerOptions is NewMultiHandlerCustom with per-handler configuration, only span group
+// name, trace ID and build ID fields of the options are used. Pass the same options to the tracer
+// via WithTracerOptions.
+func NewMultiHandlerOptions(opts TracerOptions, attrs []slog.Attr, callback MultiCallback, handlers ...slog.Handler) *MultiHandler {
 	a := make([]slog.Attr, 0, len(attrs)+1)
 	a = append(a, attrs...)
 
-	if BuildIDFieldKey != "" {
+	if key := opts.buildIDFieldKey(); key != "" {
 		a = append(a, slog.Attr{
-			Key:   BuildIDFieldKey,
+			Key:   key,
 			Value: slog.StringValue(logging.BuildID()),
 		})
 	}
@@ -60,6 +68,7 @@ func NewMultiHandlerCustom(attrs []slog.Attr, callback MultiCallback, handlers .
 	return &MultiHandler{
 		handlers: handlers,
 		callback: callback,
+		opts:     opts,
 	}
 }
 
@@ -80,9 +89,9 @@ func (h *MultiHandler) Handle(ctx context.Context, recOrig slog.Record) error {
 		attrs := make([]slog.Attr, 0, 2)
 
 		// add optional trace_id attribute
-		if id := TraceIDFromContext(ctx); id != EmptyTraceID && TraceIDFieldKey != "" {
+		if id, key := TraceIDFromContext(ctx), h.opts.traceIDFieldKey(); id != EmptyTraceID && key != "" {
 			attrs = append(attrs, slog.Attr{
-				Key:   TraceIDFieldKey,
+				Key:   key,
 				Value: slog.StringValue(id.String()),
 			})
 		}
@@ -124,6 +133,7 @@ func (h *MultiHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
 	return &MultiHandler{
 		handlers: handlers,
 		callback: h.callback,
+		opts:     h.opts,
 	}
 }
 
@@ -140,7 +150,8 @@ func (h *MultiHandler) WithGroup(name string) slog.Handler {
 	return &MultiHandler{
 		handlers:    handlers,
 		callback:    h.callback,
-		inSpanGroup: name == SpanGroupName,
+		opts:        h.opts,
+		inSpanGroup: name == h.opts.spanGroupName(),
 	}
 }
 
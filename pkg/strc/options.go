@@ -0,0 +1,131 @@
+package strc
+
+import (
+	"log/slog"
+	"time"
+)
+
+// SpanKind describes the relationship between the span, its parents and its children.
+type SpanKind int
+
+const (
+	// SpanKindInternal is the default kind, an internal operation within an application.
+	SpanKindInternal SpanKind = iota
+
+	// SpanKindServer handles an incoming synchronous request.
+	SpanKindServer
+
+	// SpanKindClient performs an outgoing synchronous request.
+	SpanKindClient
+
+	// SpanKindProducer initiates an asynchronous operation, for example enqueues a job.
+	SpanKindProducer
+
+	// SpanKindConsumer handles an asynchronous operation, for example processes a job.
+	SpanKindConsumer
+)
+
+func (k SpanKind) String() string {
+	switch k {
+	case SpanKindServer:
+		return "server"
+	case SpanKindClient:
+		return "client"
+	case SpanKindProducer:
+		return "producer"
+	case SpanKindConsumer:
+		return "consumer"
+	default:
+		return "internal"
+	}
+}
+
+// StartOption is a typed option for Start. Options can be mixed with regular arguments,
+// they are not logged as arguments:
+//
+//	span, ctx := strc.Start(ctx, "enqueue", strc.WithKind(strc.SpanKindProducer), "job_id", id)
+type StartOption func(*startConfig)
+
+type startConfig struct {
+	started time.Time
+	kind    SpanKind
+	links   []SpanContext
+	budget  time.Duration
+	attrs   []any
+
+	source    string
+	sourceSet bool
+}
+
+// WithStartTime sets the start time of the span. It is logged as "started" attribute.
+// This is the typed variant of the "started" argument.
+func WithStartTime(t time.Time) StartOption {
+	return func(c *startConfig) {
+		c.started = t
+		c.attrs = append(c.attrs, slog.Time("started", t))
+	}
+}
+
+// WithKind sets the kind of the span. It is logged as "kind" attribute unless it is
+// SpanKindInternal.
+func WithKind(kind SpanKind) StartOption {
+	return func(c *startConfig) {
+		c.kind = kind
+	}
+}
+
+// WithLinks links the span to other spans, typically from different traces. For example,
+// a job processing span can link to the span which enqueued the job. Invalid span contexts
+// are ignored. Links are logged as "links" attribute on start and end records.
+func WithLinks(links ...SpanContext) StartOption {
+	return func(c *startConfig) {
+		for _, l := range links {
+			if l.IsValid() {
+				c.links = append(c.links, l)
+			}
+		}
+	}
+}
+
+// WithAttributes adds attributes to the span. They are logged in all subsequent Event and
+// End calls just like regular arguments.
+func WithAttributes(attrs ...slog.Attr) StartOption {
+	return func(c *startConfig) {
+		for _, a := range attrs {
+			c.attrs = append(c.attrs, a)
+		}
+	}
+}
+
+// withSource sets the source of a span started by a helper on behalf of its caller, for
+// example Go or Cmd. The source is logged in both start and end records, empty source skips it.
+func withSource(source string) StartOption {
+	return func(c *startConfig) {
+		c.source = source
+		c.sourceSet = true
+	}
+}
+
+// startOptions applies all StartOption values from args and returns the configuration and
+// the remaining arguments. Arguments are returned unchanged when there are no options.
+func startOptions(args []any) (startConfig, []any) {
+	var c startConfig
+	var rest []any
+	for i, arg := range args {
+		if opt, ok := arg.(StartOption); ok {
+			if rest == nil {
+				rest = make([]any, i, len(args))
+				copy(rest, args[:i])
+			}
+			opt(&c)
+		} else if rest != nil {
+			rest = append(rest, arg)
+		}
+	}
+
+	if rest == nil {
+		return c, args
+	}
+
+	return c, append(rest, c.attrs...)
+}
@@ -0,0 +1,81 @@
+package strc
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/osbuild/logging/pkg/collect"
+)
+
+func TestStartOptions(t *testing.T) {
+	ch := collect.NewTestHandler(slog.LevelDebug, false, false, false)
+	tracer := NewTracer(slog.New(NewMultiHandler(ch)))
+
+	other, _ := tracer.Start(context.Background(), "other")
+	other.End()
+	ch.Reset()
+
+	started := time.Now().Add(-time.Hour)
+	span, _ := tracer.Start(context.Background(), "consumer",
+		WithKind(SpanKindConsumer),
+		"k1", "v1",
+		WithLinks(other.SpanContext(), SpanContext{}),
+		WithStartTime(started),
+		WithAttributes(slog.String("k2", "v2")),
+	)
+	span.End()
+
+	if span.Kind() != SpanKindConsumer {
+		t.Errorf("unexpected kind %s", span.Kind())
+	}
+	if ch.CountWith("span", "kind") != 2 || !ch.Contains("consumer", "span", "kind") {
+		t.Errorf("kind not logged on start and end: %v", ch.All())
+	}
+	if ch.CountWith("span", "k1") != 2 || ch.CountWith("span", "k2") != 2 {
+		t.Errorf("attributes not logged: %v", ch.All())
+	}
+
+	links, ok := ch.Last()["span"].(map[string]any)["links"].([]SpanContext)
+	if !ok || len(links) != 1 || links[0].TraceID != other.TraceID() {
+		t.Errorf("unexpected links: %v", ch.Last())
+	}
+
+	dur, _ := ch.Last()["span"].(map[string]any)["dur"].(time.Duration)
+	if dur < time.Hour {
+		t.Errorf("start time not applied, duration %s", dur)
+	}
+
+	for _, r := range ch.All() {
+		for _, v := range r["span"].(map[string]any) {
+			if _, ok := v.(StartOption); ok {
+				t.Errorf("option logged as argument: %v", r)
+			}
+		}
+	}
+}
+
+func TestStartTimeArgument(t *testing.T) {
+	tracer := NewTracer(slog.New(&NoopHandler{}))
+
+	started := time.Now().Add(-time.Hour)
+	span, _ := tracer.Start(context.Background(), "legacy", "started", started)
+	defer span.End()
+
+	if !span.started.Equal(started) {
+		t.Errorf("started argument not applied: %s", span.started)
+	}
+}
+
+func TestInternalKindNotLogged(t *testing.T) {
+	ch := collect.NewTestHandler(slog.LevelDebug, false, false, false)
+	tracer := NewTracer(slog.New(NewMultiHandler(ch)))
+
+	span, _ := tracer.Start(context.Background(), "internal")
+	span.End()
+
+	if ch.CountWith("span", "kind") != 0 {
+		t.Errorf("internal kind logged: %v", ch.All())
+	}
+}
@@ -0,0 +1,128 @@
+package strc
+
+import (
+	"context"
+	"runtime/pprof"
+	rtrace "runtime/trace"
+)
+
+const (
+	// ProfilingSpanLabel is the pprof label key with the span name.
+	ProfilingSpanLabel = "span"
+
+	// ProfilingTraceIDLabel is the pprof label key with the trace ID.
+	ProfilingTraceIDLabel = "trace_id"
+)
+
+// ProfilingConfig is the configuration for WithProfiling.
+type ProfilingConfig struct {
+	// TraceID adds the trace ID pprof label. Trace IDs are unique, this makes profiles large
+	// and it is only useful to find a single slow request.
+	TraceID bool
+
+	// DisableRuntimeTrace disables runtime/trace tasks and regions.
+	DisableRuntimeTrace bool
+}
+
+// WithProfiling is a TracerOption that ties CPU and heap profiles and execution traces to spans.
+// Start applies runtime/pprof labels with the span name (and optionally the trace ID) to the
+// current goroutine and the returned context, End restores labels of the context passed to
+// Start. When an execution trace is being collected, a runtime/trace task and region named
+// after the span is opened for each span.
+//
+// Data can be then sliced by span names:
+//
+//	go tool pprof -tagfocus span=query cpu.pprof
+//	go tool trace trace.out
+//
+// Goroutine labels are set by Start and restored by End, therefore both must be called from the
+// same goroutine, which is the case when End is deferred. Goroutines started with a span
+// context can apply the labels via pprof.SetGoroutineLabels, Go and Group do that
+// automatically. Labels of command spans (see Command) are not applied to any goroutine
+// because the work is done by the subprocess and Wait can be called from another goroutine.
+func WithProfiling(config ProfilingConfig) TracerOption {
+	return func(t *Tracer) {
+		t.profiling = &config
+	}
+}
+
+// spanProfile holds profiling state of a span.
+type spanProfile struct {
+	parent context.Context
+	task   *rtrace.Task
+	region *rtrace.Region
+
+	// detached is true when the span does not continue in any goroutine, end must not change
+	// labels of the goroutine it is called from
+	detached bool
+}
+
+// startProfiling applies pprof labels and starts a runtime/trace task and region for a new span.
+func (t *Tracer) startProfiling(ctx context.Context, name string, tid TraceID) (context.Context, *spanProfile) {
+	p := &spanProfile{parent: ctx}
+
+	if t.profiling.TraceID {
+		ctx = pprof.WithLabels(ctx, pprof.Labels(ProfilingSpanLabel, name, ProfilingTraceIDLabel, tid.String()))
+	} else {
+		ctx = pprof.WithLabels(ctx, pprof.Labels(ProfilingSpanLabel, name))
+	}
+	pprof.SetGoroutineLabels(ctx)
+
+	if !t.profiling.DisableRuntimeTrace && rtrace.IsEnabled() {
+		ctx, p.task = rtrace.NewTask(ctx, name)
+		p.region = rtrace.StartRegion(ctx, name)
+	}
+
+	return ctx, p
+}
+
+// detach ends the runtime/trace region and restores pprof labels of the current goroutine, it
+// is used when the span continues in a new goroutine, see attach.
+func (p *spanProfile) detach() {
+	if p.region != nil {
+		p.region.End()
+		p.region = nil
+	}
+
+	pprof.SetGoroutineLabels(p.parent)
+	p.detached = true
+}
+
+// attach applies pprof labels of the span context to the current goroutine and starts a new
+// runtime/trace region.
+func (p *spanProfile) attach(ctx context.Context, name string) {
+	pprof.SetGoroutineLabels(ctx)
+	p.detached = false
+
+	if p.task != nil {
+		p.region = rtrace.StartRegion(ctx, name)
+	}
+}
+
+// end ends the runtime/trace region and task and restores pprof labels.
+func (p *spanProfile) end() {
+	if p.region != nil {
+		p.region.End()
+	}
+	if p.task != nil {
+		p.task.End()
+	}
+
+	if !p.detached {
+		pprof.SetGoroutineLabels(p.parent)
+	}
+}
+
+// detach is called after the span was started when it continues in a new goroutine.
+func (s *Span) detach() {
+	if s.profile != nil {
+		s.profile.detach()
+	}
+}
+
+// attach is called from the new goroutine the span continues in.
+func (s *Span) attach() {
+	if s.profile != nil {
+		s.profile.attach(s.ctx, s.name)
+	}
+}
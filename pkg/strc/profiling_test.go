@@ -0,0 +1,141 @@
+package strc
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"os/exec"
+	"runtime/pprof"
+	rtrace "runtime/trace"
+	"strings"
+	"testing"
+)
+
+func goroutineLabels(t *testing.T) string {
+	t.Helper()
+
+	buf := &bytes.Buffer{}
+	if err := pprof.Lookup("goroutine").WriteTo(buf, 1); err != nil {
+		t.Fatal(err)
+	}
+	return buf.String()
+}
+
+func TestProfilingLabels(t *testing.T) {
+	tracer := NewTracer(slog.New(&NoopHandler{}), WithProfiling(ProfilingConfig{TraceID: true}))
+
+	span, ctx := tracer.Start(context.Background(), "profiled")
+	if v, _ := pprof.Label(ctx, ProfilingSpanLabel); v != "profiled" {
+		t.Errorf("unexpected span label %q", v)
+	}
+	if v, _ := pprof.Label(ctx, ProfilingTraceIDLabel); v != span.TraceID().String() {
+		t.Errorf("unexpected trace ID label %q", v)
+	}
+	if !strings.Contains(goroutineLabels(t), `"span":"profiled"`) {
+		t.Error("goroutine labels were not applied")
+	}
+
+	child, childCtx := tracer.Start(ctx, "child")
+	if v, _ := pprof.Label(childCtx, ProfilingSpanLabel); v != "child" {
+		t.Errorf("unexpected child span label %q", v)
+	}
+	child.End()
+	if !strings.Contains(goroutineLabels(t), `"span":"profiled"`) {
+		t.Error("parent goroutine labels were not restored")
+	}
+
+	span.End()
+	if strings.Contains(goroutineLabels(t), `"span":"profiled"`) {
+		t.Error("goroutine labels were not removed")
+	}
+}
+
+func TestProfilingGo(t *testing.T) {
+	tracer := NewTracer(slog.New(&NoopHandler{}), WithProfiling(ProfilingConfig{}))
+
+	done := make(chan string)
+	tracer.Go(context.Background(), "background", func(ctx context.Context) error {
+		done <- goroutineLabels(t)
+		return nil
+	})
+
+	// only the new goroutine is labeled, labels of the caller are restored
+	labels := <-done
+	if n := strings.Count(labels, `"span":"background"`); n != 1 {
+		t.Errorf("expected one labeled goroutine, got %d:\n%s", n, labels)
+	}
+}
+
+func TestProfilingCommand(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+	tracer := NewTracer(slog.New(&NoopHandler{}), WithProfiling(ProfilingConfig{}))
+
+	cmd := tracer.Command(context.Background(), "sh", "-c", "exit 0")
+	if err := cmd.Start(); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(goroutineLabels(t), `"span":"exec sh"`) {
+		t.Error("command labels were applied to the caller")
+	}
+
+	// Wait from another goroutine must not change its labels
+	done := make(chan string)
+	go func() {
+		pprof.SetGoroutineLabels(pprof.WithLabels(context.Background(), pprof.Labels("role", "waiter")))
+		_ = cmd.Wait()
+		done <- goroutineLabels(t)
+	}()
+	if labels := <-done; !strings.Contains(labels, `"role":"waiter"`) {
+		t.Errorf("labels of the waiting goroutine were changed:\n%s", labels)
+	}
+}
+
+func TestProfilingRuntimeTrace(t *testing.T) {
+	tracer := NewTracer(slog.New(&NoopHandler{}), WithProfiling(ProfilingConfig{}))
+
+	buf := &bytes.Buffer{}
+	if err := rtrace.Start(buf); err != nil {
+		t.Skipf("execution tracer is not available: %v", err)
+	}
+
+	span, ctx := tracer.Start(context.Background(), "traced-task")
+	g := tracer.NewGroup(ctx)
+	g.Go("traced-subtask", func(ctx context.Context) error { return nil })
+	if err := g.Wait(); err != nil {
+		t.Error(err)
+	}
+	span.End()
+
+	rtrace.Stop()
+	for _, name := range []string{"traced-task", "traced-subtask"} {
+		if !bytes.Contains(buf.Bytes(), []byte(name)) {
+			t.Errorf("task %s not found in the execution trace", name)
+		}
+	}
+}
+
+func BenchmarkStart(b *testing.B) {
+	benchmarks := []struct {
+		name string
+		opts []TracerOption
+	}{
+		{"default", nil},
+		{"profiling", []TracerOption{WithProfiling(ProfilingConfig{})}},
+		{"profiling_trace_id", []TracerOption{WithProfiling(ProfilingConfig{TraceID: true})}},
+	}
+
+	for _, bm := range benchmarks {
+		b.Run(bm.name, func(b *testing.B) {
+			tracer := NewTracer(slog.New(&NoopHandler{}), bm.opts...)
+			ctx := context.Background()
+
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				span, _ := tracer.Start(ctx, "bench")
+				span.End()
+			}
+		})
+	}
+}
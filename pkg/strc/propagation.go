@@ -0,0 +1,181 @@
+package strc
+
+import (
+	"context"
+	"net/http"
+)
+
+// TextMapCarrier is a storage of propagation fields, for example HTTP headers, message queue
+// headers or job arguments. See Inject and Extract.
+type TextMapCarrier interface {
+	// Get returns the value of the key or an empty string.
+	Get(key string) string
+
+	// Set stores the key-value pair, existing value is replaced.
+	Set(key, value string)
+}
+
+// HeaderCarrier adapts http.Header to TextMapCarrier.
+type HeaderCarrier http.Header
+
+var _ TextMapCarrier = HeaderCarrier{}
+
+func (c HeaderCarrier) Get(key string) string {
+	return http.Header(c).Get(key)
+}
+
+func (c HeaderCarrier) Set(key, value string) {
+	http.Header(c).Set(key, value)
+}
+
+// MapCarrier adapts map[string]string to TextMapCarrier, for example message headers. Keys
+// are case-sensitive.
+type MapCarrier map[string]string
+
+var _ TextMapCarrier = MapCarrier{}
+
+func (c MapCarrier) Get(key string) string {
+	return c[key]
+}
+
+func (c MapCarrier) Set(key, value string) {
+	c[key] = value
+}
+
+var _ TextMapCarrier = (*SpanContext)(nil)
+
+// Get implements TextMapCarrier so a SpanContext embedded in a job payload can be used with
+// Inject and Extract.
+func (sc *SpanContext) Get(key string) string {
+	switch key {
+	case TraceHTTPHeaderName:
+		if sc.TraceID != "" && sc.TraceID != EmptyTraceID {
+			return sc.TraceID.String()
+		}
+	case SpanHTTPHeaderName:
+		if sc.SpanID != "" && sc.SpanID != EmptySpanID {
+			return sc.SpanID.String()
+		}
+	case TraceparentHTTPHeaderName:
+		if sc.IsValid() {
+			return Traceparent(sc.TraceID, sc.SpanID, sc.Flags)
+		}
+	case TracestateHTTPHeaderName:
+		return sc.State
+	}
+
+	return ""
+}
+
+// Set implements TextMapCarrier so a SpanContext embedded in a job payload can be used with
+// Inject and Extract. Unknown keys and invalid values are ignored.
+func (sc *SpanContext) Set(key, value string) {
+	switch key {
+	case TraceHTTPHeaderName:
+		sc.TraceID = TraceID(value)
+	case SpanHTTPHeaderName:
+		sc.SpanID = SpanID(value)
+	case TraceparentHTTPHeaderName:
+		if _, _, flags, err := ParseTraceparent(value); err == nil {
+			sc.Flags = flags
+		}
+	case TracestateHTTPHeaderName:
+		sc.State = value
+	}
+}
+
+// Inject writes trace ID, span ID, trace flags and trace state from the context into the carrier.
+// Both X-Strc and W3C fields are written. It does nothing when there is no trace ID in the context.
+func Inject(ctx context.Context, carrier TextMapCarrier) {
+	tid := TraceIDFromContext(ctx)
+	if tid == EmptyTraceID {
+		return
+	}
+	carrier.Set(TraceHTTPHeaderName, tid.String())
+
+	sid := SpanIDFromContext(ctx)
+	if sid != EmptySpanID {
+		carrier.Set(SpanHTTPHeaderName, sid.String())
+	}
+
+	if tp := Traceparent(tid, sid, TraceFlagsFromContext(ctx)); tp != "" {
+		carrier.Set(TraceparentHTTPHeaderName, tp)
+		if ts := TraceStateFromContext(ctx); ts != "" {
+			carrier.Set(TracestateHTTPHeaderName, ts)
+		}
+	}
+}
+
+// Extract reads trace ID, span ID, trace flags and trace state from the carrier and returns a new
+// context with them. The extracted span becomes the parent of the next span started from the
+// returned context. Both X-Strc and W3C fields are supported, X-Strc fields are used when both
+// are present. Returns the context unchanged when the carrier has no trace ID.
+//
+//	ctx := strc.Extract(context.Background(), strc.MapCarrier(msg.Headers))
+//	span, ctx := strc.Start(ctx, "process message")
+//	defer span.End()
+func Extract(ctx context.Context, carrier TextMapCarrier) context.Context {
+	return ExtractWithPrecedence(ctx, carrier, PreferStrc)
+}
+
+// ExtractWithPrecedence is Extract which uses the given precedence when the carrier has both
+// X-Strc and W3C fields.
+func ExtractWithPrecedence(ctx context.Context, carrier TextMapCarrier, p Precedence) context.Context {
+	tid, sid := extractIDs(carrier, p)
+	if tid == EmptyTraceID {
+		return ctx
+	}
+
+	ctx = WithTraceID(ctx, tid)
+	if sid != EmptySpanID {
+		ctx = WithSpanID(ctx, sid)
+	}
+
+	return extractTraceparent(ctx, carrier)
+}
+
+// extractIDs returns trace ID and span ID from the carrier according to the precedence
+func extractIDs(carrier TextMapCarrier, p Precedence) (TraceID, SpanID) {
+	if useW3C(carrier, p) {
+		tid, sid, _, _ := traceparentFromCarrier(carrier)
+		return tid, sid
+	}
+
+	tid, sid := EmptyTraceID, EmptySpanID
+	if t := carrier.Get(TraceHTTPHeaderName); t != "" {
+		tid = TraceID(t)
+	}
+	if s := carrier.Get(SpanHTTPHeaderName); s != "" {
+		sid = SpanID(s)
+	}
+
+	return tid, sid
+}
+
+// extractTraceparent stores trace flags and trace state from a valid traceparent in the context
+// when it carries the trace ID stored in the context, flags and state of a traceparent ignored
+// because of the precedence are not used
+func extractTraceparent(ctx context.Context, carrier TextMapCarrier) context.Context {
+	if tid, _, flags, ok := traceparentFromCarrier(carrier); ok && tid == TraceIDFromContext(ctx) {
+		ctx = WithTraceFlags(ctx, flags)
+		if ts := carrier.Get(TracestateHTTPHeaderName); ts != "" {
+			ctx = WithTraceState(ctx, ts)
+		}
+	}
+
+	return ctx
+}
+
+func traceparentFromCarrier(carrier TextMapCarrier) (TraceID, SpanID, TraceFlags, bool) {
+	tp := carrier.Get(TraceparentHTTPHeaderName)
+	if tp == "" {
+		return EmptyTraceID, EmptySpanID, 0, false
+	}
+
+	tid, sid, flags, err := ParseTraceparent(tp)
+	if err != nil {
+		return EmptyTraceID, EmptySpanID, 0, false
+	}
+
+	return tid, sid, flags, true
+}
@@ -0,0 +1,116 @@
+package strc
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"testing"
+)
+
+func TestInjectExtract(t *testing.T) {
+	tracer := NewTracer(slog.New(&NoopHandler{}))
+	span, ctx := tracer.Start(context.Background(), "producer")
+	defer span.End()
+	ctx = WithTraceState(ctx, "vendor=value")
+
+	carriers := map[string]TextMapCarrier{
+		"header": HeaderCarrier(http.Header{}),
+		"map":    MapCarrier{},
+		"json":   &SpanContext{},
+	}
+
+	for name, carrier := range carriers {
+		t.Run(name, func(t *testing.T) {
+			Inject(ctx, carrier)
+
+			// simulate a queue hop for the JSON payload
+			if sc, ok := carrier.(*SpanContext); ok {
+				buf, err := json.Marshal(sc)
+				if err != nil {
+					t.Fatal(err)
+				}
+				carrier = &SpanContext{}
+				if err := json.Unmarshal(buf, carrier); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			remote := Extract(context.Background(), carrier)
+			if TraceIDFromContext(remote) != span.TraceID() {
+				t.Errorf("unexpected trace ID %s", TraceIDFromContext(remote))
+			}
+			if SpanIDFromContext(remote) != SpanIDFromContext(ctx) {
+				t.Errorf("unexpected span ID %s", SpanIDFromContext(remote))
+			}
+			if TraceStateFromContext(remote) != "vendor=value" {
+				t.Errorf("unexpected trace state %q", TraceStateFromContext(remote))
+			}
+
+			consumer, cctx := tracer.Start(remote, "consumer")
+			defer consumer.End()
+			if consumer.TraceID() != span.TraceID() {
+				t.Errorf("consumer is not in the producer trace")
+			}
+			if SpanIDFromContext(cctx).ParentID() != SpanIDFromContext(ctx).ID() {
+				t.Errorf("consumer parent %s, want %s", SpanIDFromContext(cctx).ParentID(), SpanIDFromContext(ctx).ID())
+			}
+		})
+	}
+}
+
+func TestExtractSampledFlag(t *testing.T) {
+	carrier := MapCarrier{
+		TraceparentHTTPHeaderName: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00",
+	}
+
+	ctx := Extract(context.Background(), carrier)
+	if TraceIDFromContext(ctx) != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Errorf("unexpected trace ID %s", TraceIDFromContext(ctx))
+	}
+	if IsSampled(ctx) {
+		t.Error("sampled flag was not extracted")
+	}
+}
+
+func TestExtractTraceparentPrecedence(t *testing.T) {
+	carrier := MapCarrier{
+		TraceHTTPHeaderName:       "LOlIxiHprrrvHqD",
+		SpanHTTPHeaderName:        "VIPEcES.yuufaHI",
+		TraceparentHTTPHeaderName: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00",
+		TracestateHTTPHeaderName:  "vendor=value",
+	}
+
+	// flags and state of an unused traceparent are ignored
+	ctx := Extract(context.Background(), carrier)
+	if TraceIDFromContext(ctx) != "LOlIxiHprrrvHqD" || !IsSampled(ctx) || TraceStateFromContext(ctx) != "" {
+		t.Errorf("unexpected context %s %s %q", TraceIDFromContext(ctx), TraceFlagsFromContext(ctx), TraceStateFromContext(ctx))
+	}
+
+	r := &http.Request{Header: http.Header{}}
+	for k, v := range carrier {
+		r.Header.Set(k, v)
+	}
+	_, r = httpRequestWithTracing(r, PreferStrc)
+	if !IsSampled(r.Context()) || TraceStateFromContext(r.Context()) != "" {
+		t.Errorf("unexpected request context %s %q", TraceFlagsFromContext(r.Context()), TraceStateFromContext(r.Context()))
+	}
+
+	ctx = ExtractWithPrecedence(context.Background(), carrier, PreferW3C)
+	if TraceIDFromContext(ctx) != "4bf92f3577b34da6a3ce929d0e0e4736" || IsSampled(ctx) || TraceStateFromContext(ctx) != "vendor=value" {
+		t.Errorf("unexpected context %s %s %q", TraceIDFromContext(ctx), TraceFlagsFromContext(ctx), TraceStateFromContext(ctx))
+	}
+}
+
+func TestExtractEmpty(t *testing.T) {
+	ctx := context.Background()
+	if Extract(ctx, MapCarrier{}) != ctx {
+		t.Error("context changed for an empty carrier")
+	}
+
+	m := MapCarrier{}
+	Inject(ctx, m)
+	if len(m) != 0 {
+		t.Errorf("unexpected fields injected: %v", m)
+	}
+}
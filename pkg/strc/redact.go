@@ -0,0 +1,254 @@
+package strc
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"path"
+	"regexp"
+	"strings"
+)
+
+var _ slog.Handler = (*RedactHandler)(nil)
+
+// DefaultRedactMask is the default replacement of masked values.
+const DefaultRedactMask = "[REDACTED]"
+
+// RedactAction is the action applied to a matched attribute.
+type RedactAction int
+
+const (
+	// RedactMask replaces the value with RedactConfig.Mask.
+	RedactMask RedactAction = iota
+
+	// RedactDrop removes the attribute from the record.
+	RedactDrop
+
+	// RedactHMAC replaces the value with a keyed HMAC-SHA256 pseudonym, the same value always
+	// results in the same pseudonym so records can be correlated without storing the value.
+	RedactHMAC
+)
+
+// RedactRule matches attributes by key or value.
+type RedactRule struct {
+	// Keys are attribute key names or glob patterns (path.Match syntax) matched case-insensitive
+	// against both the key and the full path with groups separated by dots, for example
+	// "password", "*token*" or "request.headers.*". The whole value is redacted, including
+	// groups. Invalid patterns never match.
+	Keys []string
+
+	// Values are regular expressions matched against string values and string forms of errors,
+	// fmt.Stringer and []string values. Only the matched parts are masked or pseudonymized and
+	// the result is a string, the whole attribute is dropped for RedactDrop.
+	Values []*regexp.Regexp
+
+	// Action is the action applied to matched attributes, defaults to RedactMask.
+	Action RedactAction
+}
+
+// RedactConfig is the configuration for RedactHandler.
+type RedactConfig struct {
+	// Rules are evaluated in order, the first rule matching the key wins. Value rules are
+	// applied when no key rule matched.
+	Rules []RedactRule
+
+	// Mask is the replacement for RedactMask. Defaults to DefaultRedactMask.
+	Mask string
+
+	// HMACKey is the secret key for RedactHMAC, it is required when any rule uses RedactHMAC.
+	// Keep it stable across restarts and instances, otherwise pseudonyms do not correlate.
+	HMACKey []byte
+}
+
+// RedactHandler is an slog.Handler wrapper which drops, masks or pseudonymizes sensitive
+// attributes before they are forwarded to the next handler. Use it inside MultiHandler to
+// redact also attributes added by MultiHandler and spans:
+//
+//	redact := strc.NewRedactHandler(splunkHandler, strc.RedactConfig{
+//		Rules: []strc.RedactRule{
+//			{Keys: []string{"password", "*token*"}},
+//			{Values: []*regexp.Regexp{emailRegexp}, Action: strc.RedactHMAC},
+//		},
+//		HMACKey: key,
+//	})
+//	logger := slog.New(strc.NewMultiHandler(redact))
+//
+// Values implementing slog.LogValuer are resolved before matching. Message is not redacted.
+type RedactHandler struct {
+	next   slog.Handler
+	config *RedactConfig
+	groups []string
+}
+
+// NewRedactHandler creates a new redaction handler forwarding to the next handler. It panics
+// when a rule uses RedactHMAC without HMACKey, unkeyed pseudonyms can be reversed by a dictionary.
+func NewRedactHandler(next slog.Handler, config RedactConfig) *RedactHandler {
+	for _, rule := range config.Rules {
+		if rule.Action == RedactHMAC && len(config.HMACKey) == 0 {
+			panic("RedactHMAC rule requires HMACKey")
+		}
+	}
+
+	if config.Mask == "" {
+		config.Mask = DefaultRedactMask
+	}
+
+	return &RedactHandler{
+		next:   next,
+		config: &config,
+	}
+}
+
+func (h *RedactHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *RedactHandler) Handle(ctx context.Context, r slog.Record) error {
+	nr := slog.NewRecord(r.Time, r.Level, r.Message, r.PC)
+	r.Attrs(func(a slog.Attr) bool {
+		if a, ok := h.redact(h.groups, a); ok {
+			nr.AddAttrs(a)
+		}
+		return true
+	})
+
+	return h.next.Handle(ctx, nr)
+}
+
+func (h *RedactHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	redacted := make([]slog.Attr, 0, len(attrs))
+	for _, a := range attrs {
+		if a, ok := h.redact(h.groups, a); ok {
+			redacted = append(redacted, a)
+		}
+	}
+
+	return &RedactHandler{
+		next:   h.next.WithAttrs(redacted),
+		config: h.config,
+		groups: h.groups,
+	}
+}
+
+func (h *RedactHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+
+	return &RedactHandler{
+		next:   h.next.WithGroup(name),
+		config: h.config,
+		groups: append(h.groups[:len(h.groups):len(h.groups)], name),
+	}
+}
+
+// redact returns the redacted attribute or false when it must be dropped.
+func (h *RedactHandler) redact(groups []string, a slog.Attr) (slog.Attr, bool) {
+	a.Value = a.Value.Resolve()
+
+	if a.Key != "" {
+		if rule := h.matchKey(groups, a.Key); rule != nil {
+			switch rule.Action {
+			case RedactDrop:
+				return a, false
+			case RedactHMAC:
+				return slog.String(a.Key, h.pseudonym(a.Value.String())), true
+			default:
+				return slog.String(a.Key, h.config.Mask), true
+			}
+		}
+	}
+
+	if a.Value.Kind() == slog.KindGroup {
+		if a.Key != "" {
+			groups = append(groups[:len(groups):len(groups)], a.Key)
+		}
+		var attrs []slog.Attr
+		for _, ga := range a.Value.Group() {
+			if ga, ok := h.redact(groups, ga); ok {
+				attrs = append(attrs, ga)
+			}
+		}
+		return slog.Attr{Key: a.Key, Value: slog.GroupValue(attrs...)}, true
+	}
+
+	if !stringLike(a.Value) {
+		return a, true
+	}
+
+	s := a.Value.String()
+	matched := false
+	for _, rule := range h.config.Rules {
+		for _, re := range rule.Values {
+			if !re.MatchString(s) {
+				continue
+			}
+
+			matched = true
+			switch rule.Action {
+			case RedactDrop:
+				return a, false
+			case RedactHMAC:
+				s = re.ReplaceAllStringFunc(s, h.pseudonym)
+			default:
+				s = re.ReplaceAllLiteralString(s, h.config.Mask)
+			}
+		}
+	}
+
+	if !matched {
+		return a, true
+	}
+
+	return slog.String(a.Key, s), true
+}
+
+// stringLike returns true for values which are matched by value rules in their string form.
+func stringLike(v slog.Value) bool {
+	if v.Kind() == slog.KindString {
+		return true
+	}
+	if v.Kind() != slog.KindAny {
+		return false
+	}
+
+	switch v.Any().(type) {
+	case error, fmt.Stringer, []string:
+		return true
+	default:
+		return false
+	}
+}
+
+// matchKey returns the first rule matching the key or its full path.
+func (h *RedactHandler) matchKey(groups []string, key string) *RedactRule {
+	key = strings.ToLower(key)
+	full := key
+	if len(groups) > 0 {
+		full = strings.ToLower(strings.Join(groups, ".")) + "." + key
+	}
+
+	for i := range h.config.Rules {
+		for _, pattern := range h.config.Rules[i].Keys {
+			pattern = strings.ToLower(pattern)
+			if ok, _ := path.Match(pattern, key); ok {
+				return &h.config.Rules[i]
+			}
+			if ok, _ := path.Match(pattern, full); ok {
+				return &h.config.Rules[i]
+			}
+		}
+	}
+
+	return nil
+}
+
+// pseudonym returns "hmac:" followed by the first 16 hex characters of HMAC-SHA256 of the value.
+func (h *RedactHandler) pseudonym(value string) string {
+	mac := hmac.New(sha256.New, h.config.HMACKey)
+	mac.Write([]byte(value))
+	return "hmac:" + hex.EncodeToString(mac.Sum(nil))[:16]
+}
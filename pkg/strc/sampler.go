@@ -0,0 +1,101 @@
+package strc
+
+import (
+	"context"
+	"hash/fnv"
+	"math"
+)
+
+// Sampler decides whether a new span is sampled. Unsampled spans are not logged but they
+// still create trace and span IDs, so trace_id correlation of regular log records keeps
+// working. The decision is stored in the context as W3C trace flags and it is propagated
+// to other services via HTTP headers.
+type Sampler interface {
+	// ShouldSample returns true when the span with the given name and trace ID should be
+	// sampled. The context contains the parent span information and its decision, if any.
+	ShouldSample(ctx context.Context, traceID TraceID, name string) bool
+}
+
+// SamplerFunc is an adapter to allow the use of ordinary functions as samplers.
+type SamplerFunc func(ctx context.Context, traceID TraceID, name string) bool
+
+func (f SamplerFunc) ShouldSample(ctx context.Context, traceID TraceID, name string) bool {
+	return f(ctx, traceID, name)
+}
+
+// AlwaysSample returns a sampler which samples every span, use it with ParentBased to respect
+// decisions of parent spans.
+func AlwaysSample() Sampler {
+	return SamplerFunc(func(context.Context, TraceID, string) bool {
+		return true
+	})
+}
+
+// NeverSample returns a sampler which does not sample any span.
+func NeverSample() Sampler {
+	return SamplerFunc(func(context.Context, TraceID, string) bool {
+		return false
+	})
+}
+
+// TraceIDRatioBased returns a sampler which samples the given fraction of traces. The decision
+// is keyed on the trace ID, therefore all spans of a trace share the same decision and so do
+// all services configured with the same ratio. Ratio equal or higher than 1 samples everything,
+// zero or lower samples nothing.
+func TraceIDRatioBased(ratio float64) Sampler {
+	if ratio >= 1 {
+		return AlwaysSample()
+	}
+	if ratio <= 0 {
+		return NeverSample()
+	}
+
+	bound := uint64(ratio * math.MaxUint64)
+	return SamplerFunc(func(_ context.Context, traceID TraceID, _ string) bool {
+		h := fnv.New64a()
+		_, _ = h.Write([]byte(traceID))
+		return h.Sum64() < bound
+	})
+}
+
+// ParentBased returns a sampler which respects the decision of the parent span, either a
+// local span or a remote one propagated via HTTP headers. When there is no parent decision,
+// the root sampler is used.
+func ParentBased(root Sampler) Sampler {
+	return SamplerFunc(func(ctx context.Context, traceID TraceID, name string) bool {
+		if flags, ok := traceFlagsFromContext(ctx); ok {
+			return flags.Sampled()
+		}
+
+		return root.ShouldSample(ctx, traceID, name)
+	})
+}
+
+// defaultSampler samples every span unless the parent decided otherwise.
+var defaultSampler = ParentBased(AlwaysSample())
+
+// WithSampler is a TracerOption that sets a sampler for the tracer. Default sampler is
+// ParentBased(AlwaysSample()), so a decision from an incoming request is respected.
+func WithSampler(s Sampler) TracerOption {
+	return func(t *Tracer) {
+		t.sampler = s
+	}
+}
+
+// IsSampled returns true when the context carries a positive sampling decision or no decision
+// at all. Use this to skip expensive work for traces that are not sampled.
+func IsSampled(ctx context.Context) bool {
+	return TraceFlagsFromContext(ctx).Sampled()
+}
+
+func traceFlagsFromContext(ctx context.Context) (TraceFlags, bool) {
+	if ctx == nil {
+		return 0, false
+	}
+
+	if v := ctx.Value(traceFlagsKey); v != nil {
+		return v.(TraceFlags), true
+	}
+
+	return 0, false
+}
@@ -0,0 +1,92 @@
+package strc
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"testing"
+
+	"github.com/osbuild/logging/pkg/collect"
+)
+
+func TestTraceIDRatioBased(t *testing.T) {
+	g := NewSeededIDGenerator(AlphaFormat, 0)
+	s := TraceIDRatioBased(0.25)
+
+	sampled := 0
+	for i := 0; i < 10000; i++ {
+		tid := g.NewTraceID()
+		decision := s.ShouldSample(context.Background(), tid, "test")
+		if decision != s.ShouldSample(context.Background(), tid, "other") {
+			t.Fatalf("decision for trace %s is not stable", tid)
+		}
+		if decision {
+			sampled++
+		}
+	}
+
+	if sampled < 2200 || sampled > 2800 {
+		t.Errorf("sampled %d traces out of 10000, want around 2500", sampled)
+	}
+
+	if !TraceIDRatioBased(1).ShouldSample(context.Background(), "a", "") {
+		t.Error("ratio 1 must sample everything")
+	}
+	if TraceIDRatioBased(0).ShouldSample(context.Background(), "a", "") {
+		t.Error("ratio 0 must sample nothing")
+	}
+}
+
+func TestParentBased(t *testing.T) {
+	s := ParentBased(NeverSample())
+
+	if s.ShouldSample(context.Background(), "a", "") {
+		t.Error("root decision must be delegated")
+	}
+	if !s.ShouldSample(WithTraceFlags(context.Background(), FlagSampled), "a", "") {
+		t.Error("sampled parent must be respected")
+	}
+	if ParentBased(AlwaysSample()).ShouldSample(WithTraceFlags(context.Background(), 0), "a", "") {
+		t.Error("unsampled parent must be respected")
+	}
+}
+
+func TestTracerSampler(t *testing.T) {
+	SetIDGenerator(NewSeededIDGenerator(AlphaFormat, 0))
+	ch := collect.NewTestHandler(slog.LevelDebug, false, false, false)
+	tracer := NewTracer(slog.New(NewMultiHandler(ch)), WithSampler(ParentBased(NeverSample())))
+
+	span, ctx := tracer.Start(context.Background(), "root")
+	if span.IsSampled() || IsSampled(ctx) {
+		t.Error("root span must not be sampled")
+	}
+	if span.TraceID() == EmptyTraceID || SpanIDFromContext(ctx) == EmptySpanID {
+		t.Error("unsampled span must have IDs")
+	}
+
+	child, childCtx := tracer.Start(ctx, "child")
+	child.Event("event")
+	child.End()
+	span.End()
+
+	// regular logs are still correlated
+	slog.New(NewMultiHandler(ch)).InfoContext(childCtx, "message")
+	if ch.Count() != 1 || !ch.Contains(span.TraceID().String(), TraceIDFieldKey) {
+		t.Errorf("unexpected records: %v", ch.All())
+	}
+
+	// decision is propagated downstream
+	r := &http.Request{Header: http.Header{}}
+	AddTraceHeaders(childCtx, r)
+	if _, _, flags, ok := TraceparentFromRequest(r); !ok || flags.Sampled() {
+		t.Errorf("unexpected traceparent %q", r.Header.Get(TraceparentHTTPHeaderName))
+	}
+
+	// remote sampled decision wins with parent-based sampler
+	ctx = WithTraceFlags(context.Background(), FlagSampled)
+	span, _ = tracer.Start(ctx, "remote")
+	span.End()
+	if !span.IsSampled() || ch.CountWith("span", "name") != 2 {
+		t.Errorf("unexpected records: %v", ch.All())
+	}
+}
@@ -0,0 +1,136 @@
+package strc
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+)
+
+// SpanData is a finished span passed to a SpanExporter. Unlike records of ExportHandler, it does
+// not depend on the slog group layout.
+type SpanData struct {
+	// TraceID is the trace ID of the span.
+	TraceID TraceID
+
+	// SpanID is the span ID, use SpanID.ParentID to get the parent span ID.
+	SpanID SpanID
+
+	// Flags are W3C trace flags of the span.
+	Flags TraceFlags
+
+	// Name is the name of the span.
+	Name string
+
+	// Kind is the kind of the span.
+	Kind SpanKind
+
+	// Start is the start time of the span.
+	Start time.Time
+
+	// End is the end time of the span.
+	End time.Time
+
+	// Attributes are Start arguments, attributes set via SetAttributes and End arguments.
+	Attributes []slog.Attr
+
+	// Events are span events in the order they were logged.
+	Events []EventData
+
+	// Links are linked span contexts.
+	Links []SpanContext
+
+	// Status is the status code of the span.
+	Status StatusCode
+
+	// StatusDescription is the status description and recorded errors of a failed span.
+	StatusDescription string
+
+	// Source is the file and line where the span was started, empty when SkipSource is set.
+	Source string
+}
+
+// EventData is an event of a finished span.
+type EventData struct {
+	// Name is the name of the event.
+	Name string
+
+	// Time is the time of the event.
+	Time time.Time
+
+	// Attributes are Event arguments.
+	Attributes []slog.Attr
+}
+
+// SpanExporter exports finished spans to an external system, see BatchSpanProcessor.
+// Implementations must be safe for concurrent use.
+type SpanExporter interface {
+	// ExportSpans exports a batch of spans. The slice must not be retained.
+	ExportSpans(ctx context.Context, spans []SpanData) error
+
+	// Shutdown flushes and releases resources of the exporter.
+	Shutdown(ctx context.Context) error
+}
+
+// SpanProcessor receives finished sampled spans. Implementations must be safe for concurrent
+// use and must not block.
+type SpanProcessor interface {
+	// OnEnd is called when a span ends.
+	OnEnd(span SpanData)
+}
+
+// SpanStartProcessor is an optional interface of SpanProcessor. OnStart is called when a sampled
+// span starts, implementations must not block.
+type SpanStartProcessor interface {
+	OnStart(span *Span)
+}
+
+// WithSpanProcessor is a TracerOption that adds a span processor to the tracer. Sampled spans
+// are passed to all processors when they end, regardless of the logging level.
+func WithSpanProcessor(p SpanProcessor) TracerOption {
+	return func(t *Tracer) {
+		t.processors = append(t.processors, p)
+	}
+}
+
+// InMemoryExporter is a SpanExporter which keeps all spans in memory. Only intended for tests.
+type InMemoryExporter struct {
+	mu    sync.Mutex
+	spans []SpanData
+}
+
+var _ SpanExporter = (*InMemoryExporter)(nil)
+
+// NewInMemoryExporter returns a new in-memory exporter.
+func NewInMemoryExporter() *InMemoryExporter {
+	return &InMemoryExporter{}
+}
+
+func (e *InMemoryExporter) ExportSpans(_ context.Context, spans []SpanData) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.spans = append(e.spans, spans...)
+	return nil
+}
+
+func (e *InMemoryExporter) Shutdown(context.Context) error {
+	return nil
+}
+
+// Spans returns a copy of all exported spans.
+func (e *InMemoryExporter) Spans() []SpanData {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return slices.Clone(e.spans)
+}
+
+// Reset removes all exported spans.
+func (e *InMemoryExporter) Reset() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.spans = nil
+}
@@ -0,0 +1,262 @@
+package strc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultMaxQueueSize is the default maximum number of spans waiting for export.
+	DefaultMaxQueueSize = 2048
+
+	// DefaultMaxBatchSize is the default maximum number of spans exported at once.
+	DefaultMaxBatchSize = 512
+
+	// DefaultFlushInterval is the default interval between periodic exports.
+	DefaultFlushInterval = 5 * time.Second
+
+	// DefaultExportTimeout is the default timeout of a single export call.
+	DefaultExportTimeout = 30 * time.Second
+)
+
+// BatchSpanProcessorConfig is the configuration for BatchSpanProcessor.
+type BatchSpanProcessorConfig struct {
+	// MaxQueueSize is the maximum number of spans waiting for export, new spans are dropped
+	// when the queue is full. Defaults to DefaultMaxQueueSize.
+	MaxQueueSize int
+
+	// MaxBatchSize is the maximum number of spans exported at once. Defaults to
+	// DefaultMaxBatchSize.
+	MaxBatchSize int
+
+	// FlushInterval is the interval between periodic exports. Defaults to DefaultFlushInterval.
+	FlushInterval time.Duration
+
+	// ExportTimeout is the timeout of a single export call. Defaults to DefaultExportTimeout.
+	ExportTimeout time.Duration
+}
+
+// BatchSpanProcessorStats are statistics of BatchSpanProcessor.
+type BatchSpanProcessorStats struct {
+	// Total number of exported spans
+	SpanCount uint64
+
+	// Total number of export calls
+	BatchCount uint64
+
+	// Total number of failed export calls
+	ErrorCount uint64
+
+	// Total number of spans dropped because the queue was full or the processor was closed
+	DroppedCount uint64
+}
+
+// ErrCloseTimeout is returned when the processor was not closed within the timeout.
+var ErrCloseTimeout = errors.New("close timeout reached")
+
+// BatchSpanProcessor is a SpanProcessor which queues finished spans in a bounded queue and
+// exports them in batches in a background goroutine, when a batch is full or periodically.
+// Call CloseWithTimeout before the application exits to export queued spans.
+type BatchSpanProcessor struct {
+	exporter SpanExporter
+	config   BatchSpanProcessorConfig
+
+	queue   chan SpanData
+	flushes chan chan error
+	stop    chan struct{}
+	done    chan struct{}
+
+	closeOnce sync.Once
+	closeMu   sync.RWMutex
+	closed    bool
+
+	stats   BatchSpanProcessorStats
+	statsMu sync.Mutex
+}
+
+var _ SpanProcessor = (*BatchSpanProcessor)(nil)
+
+// NewBatchSpanProcessor creates a new batch span processor and starts its background goroutine.
+func NewBatchSpanProcessor(exporter SpanExporter, config BatchSpanProcessorConfig) *BatchSpanProcessor {
+	if config.MaxQueueSize <= 0 {
+		config.MaxQueueSize = DefaultMaxQueueSize
+	}
+	if config.MaxBatchSize <= 0 {
+		config.MaxBatchSize = DefaultMaxBatchSize
+	}
+	if config.FlushInterval <= 0 {
+		config.FlushInterval = DefaultFlushInterval
+	}
+	if config.ExportTimeout <= 0 {
+		config.ExportTimeout = DefaultExportTimeout
+	}
+
+	p := &BatchSpanProcessor{
+		exporter: exporter,
+		config:   config,
+		queue:    make(chan SpanData, config.MaxQueueSize),
+		flushes:  make(chan chan error),
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	go p.run()
+
+	return p
+}
+
+// OnEnd enqueues the span for export, it never blocks. The span is dropped when the queue
+// is full or the processor was closed.
+func (p *BatchSpanProcessor) OnEnd(span SpanData) {
+	p.closeMu.RLock()
+	defer p.closeMu.RUnlock()
+
+	if !p.closed {
+		select {
+		case p.queue <- span:
+			return
+		default:
+		}
+	}
+
+	p.statsMu.Lock()
+	p.stats.DroppedCount++
+	p.statsMu.Unlock()
+}
+
+// Flush exports all queued spans and blocks until the export finishes. Returns the export
+// error, if any.
+func (p *BatchSpanProcessor) Flush() error {
+	ch := make(chan error, 1)
+	select {
+	case p.flushes <- ch:
+	case <-p.done:
+		return nil
+	}
+
+	select {
+	case err := <-ch:
+		return err
+	case <-p.done:
+		return nil
+	}
+}
+
+// CloseWithTimeout exports all queued spans and shuts the exporter down, blocks not longer than
+// the timeout. It is safe to call it multiple times, new spans are dropped after the call.
+//
+// Returns ErrCloseTimeout if timeout was reached.
+func (p *BatchSpanProcessor) CloseWithTimeout(timeout time.Duration) error {
+	var result error
+	p.closeOnce.Do(func() {
+		p.closeMu.Lock()
+		p.closed = true
+		p.closeMu.Unlock()
+		close(p.stop)
+
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+
+		select {
+		case <-p.done:
+		case <-ctx.Done():
+			result = ErrCloseTimeout
+			return
+		}
+
+		result = p.exporter.Shutdown(ctx)
+	})
+
+	return result
+}
+
+// Stats returns a copy of the current statistics. It is safe to call this method concurrently.
+func (p *BatchSpanProcessor) Stats() BatchSpanProcessorStats {
+	p.statsMu.Lock()
+	defer p.statsMu.Unlock()
+
+	return p.stats
+}
+
+func (p *BatchSpanProcessor) run() {
+	defer close(p.done)
+
+	ticker := time.NewTicker(p.config.FlushInterval)
+	defer ticker.Stop()
+
+	batch := make([]SpanData, 0, p.config.MaxBatchSize)
+	for {
+		select {
+		case span := <-p.queue:
+			batch = append(batch, span)
+			if len(batch) >= p.config.MaxBatchSize {
+				batch = p.report(p.export(batch))
+			}
+		case <-ticker.C:
+			batch = p.report(p.export(batch))
+		case ch := <-p.flushes:
+			var err error
+			batch, err = p.drain(batch)
+			ch <- err
+		case <-p.stop:
+			batch, err := p.drain(batch)
+			p.report(batch, err)
+			return
+		}
+	}
+}
+
+// drain exports the batch and all queued spans
+func (p *BatchSpanProcessor) drain(batch []SpanData) ([]SpanData, error) {
+	var errs []error
+	for {
+		select {
+		case span := <-p.queue:
+			batch = append(batch, span)
+			if len(batch) >= p.config.MaxBatchSize {
+				var err error
+				batch, err = p.export(batch)
+				errs = append(errs, err)
+			}
+		default:
+			var err error
+			batch, err = p.export(batch)
+			return batch, errors.Join(append(errs, err)...)
+		}
+	}
+}
+
+// export exports the batch and returns it emptied for reuse
+func (p *BatchSpanProcessor) export(batch []SpanData) ([]SpanData, error) {
+	if len(batch) == 0 {
+		return batch, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), p.config.ExportTimeout)
+	defer cancel()
+
+	err := p.exporter.ExportSpans(ctx, batch)
+
+	p.statsMu.Lock()
+	p.stats.BatchCount++
+	if err != nil {
+		p.stats.ErrorCount++
+	} else {
+		p.stats.SpanCount += uint64(len(batch))
+	}
+	p.statsMu.Unlock()
+
+	clear(batch)
+	return batch[:0], err
+}
+
+func (p *BatchSpanProcessor) report(batch []SpanData, err error) []SpanData {
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "span processor unable to export spans: %v\n", err)
+	}
+
+	return batch
+}
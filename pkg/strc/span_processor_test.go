@@ -0,0 +1,148 @@
+package strc
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestBatchSpanProcessor(t *testing.T) {
+	exporter := NewInMemoryExporter()
+	processor := NewBatchSpanProcessor(exporter, BatchSpanProcessorConfig{})
+	defer processor.CloseWithTimeout(time.Second)
+
+	// logging is disabled, spans are exported anyway
+	tracer := NewTracer(slog.New(&NoopHandler{}), WithSpanProcessor(processor))
+
+	link := SpanContext{TraceID: "bqzcRlJahlbbBZH", SpanID: "0000000.IvQORsV"}
+	span, ctx := tracer.Start(context.Background(), "parent", "k1", "v1", WithKind(SpanKindServer), WithLinks(link))
+	child, _ := tracer.Start(ctx, "child")
+	child.Event("event", "e1", 1)
+	child.SetAttributes(slog.String("k2", "v2"))
+	child.EndWithError(errors.New("failed"), "k3", "v3")
+	span.End()
+
+	if err := processor.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	spans := exporter.Spans()
+	if len(spans) != 2 {
+		t.Fatalf("exported %d spans, want 2", len(spans))
+	}
+
+	c, p := spans[0], spans[1]
+	if c.Name != "child" || c.TraceID != p.TraceID || c.SpanID.ParentID() != p.SpanID.ID() {
+		t.Errorf("unexpected child span %+v", c)
+	}
+	if len(c.Events) != 1 || c.Events[0].Name != "event" || c.Events[0].Attributes[0].Key != "e1" {
+		t.Errorf("unexpected events %+v", c.Events)
+	}
+	if len(c.Attributes) != 2 || c.Attributes[0].Key != "k2" || c.Attributes[1].Key != "k3" {
+		t.Errorf("unexpected attributes %+v", c.Attributes)
+	}
+	if c.Status != StatusError || c.StatusDescription != "failed" || c.End.Before(c.Start) {
+		t.Errorf("unexpected status %+v", c)
+	}
+	if p.Kind != SpanKindServer || len(p.Links) != 1 || p.Links[0] != link || p.Attributes[0].String() != "k1=v1" {
+		t.Errorf("unexpected parent span %+v", p)
+	}
+
+	stats := processor.Stats()
+	if stats.SpanCount != 2 || stats.DroppedCount != 0 {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+}
+
+func TestBatchSpanProcessorUnsampled(t *testing.T) {
+	exporter := NewInMemoryExporter()
+	processor := NewBatchSpanProcessor(exporter, BatchSpanProcessorConfig{})
+	defer processor.CloseWithTimeout(time.Second)
+
+	tracer := NewTracer(slog.New(&NoopHandler{}), WithSpanProcessor(processor), WithSampler(NeverSample()))
+	span, _ := tracer.Start(context.Background(), "unsampled")
+	span.End()
+
+	if err := processor.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if len(exporter.Spans()) != 0 {
+		t.Errorf("unsampled span exported")
+	}
+}
+
+func TestBatchSpanProcessorBatching(t *testing.T) {
+	exporter := &countingExporter{}
+	processor := NewBatchSpanProcessor(exporter, BatchSpanProcessorConfig{MaxBatchSize: 10, FlushInterval: time.Hour})
+	tracer := NewTracer(slog.New(&NoopHandler{}), WithSpanProcessor(processor))
+
+	for i := 0; i < 25; i++ {
+		span, _ := tracer.Start(context.Background(), "span")
+		span.End()
+	}
+
+	if err := processor.CloseWithTimeout(time.Second); err != nil {
+		t.Fatal(err)
+	}
+	if exporter.spans.Load() != 25 || exporter.batches.Load() != 3 || !exporter.shutdown.Load() {
+		t.Errorf("unexpected exports: %d spans in %d batches", exporter.spans.Load(), exporter.batches.Load())
+	}
+
+	// closed processor drops spans
+	span, _ := tracer.Start(context.Background(), "dropped")
+	span.End()
+	if processor.Stats().DroppedCount != 1 {
+		t.Errorf("unexpected stats %+v", processor.Stats())
+	}
+}
+
+func TestBatchSpanProcessorPeriodic(t *testing.T) {
+	exporter := NewInMemoryExporter()
+	processor := NewBatchSpanProcessor(exporter, BatchSpanProcessorConfig{FlushInterval: time.Millisecond})
+	defer processor.CloseWithTimeout(time.Second)
+
+	processor.OnEnd(SpanData{Name: "periodic"})
+	for i := 0; i < 100 && len(exporter.Spans()) == 0; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if len(exporter.Spans()) != 1 {
+		t.Error("span was not exported periodically")
+	}
+}
+
+func TestBatchSpanProcessorCloseTimeout(t *testing.T) {
+	block := make(chan struct{})
+	defer close(block)
+	exporter := &countingExporter{block: block}
+	processor := NewBatchSpanProcessor(exporter, BatchSpanProcessorConfig{})
+
+	processor.OnEnd(SpanData{Name: "stuck"})
+	if err := processor.CloseWithTimeout(10 * time.Millisecond); !errors.Is(err, ErrCloseTimeout) {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+type countingExporter struct {
+	spans    atomic.Int64
+	batches  atomic.Int64
+	shutdown atomic.Bool
+	block    chan struct{}
+}
+
+func (e *countingExporter) ExportSpans(_ context.Context, spans []SpanData) error {
+	if e.block != nil {
+		<-e.block
+	}
+	e.spans.Add(int64(len(spans)))
+	e.batches.Add(1)
+	return nil
+}
+
+func (e *countingExporter) Shutdown(context.Context) error {
+	e.shutdown.Store(true)
+	return nil
+}
@@ -0,0 +1,111 @@
+package strc
+
+import (
+	"errors"
+	"log/slog"
+)
+
+// StatusCode is the status of a span.
+type StatusCode int
+
+const (
+	// StatusUnset is the default status, no status attribute is logged.
+	StatusUnset StatusCode = iota
+
+	// StatusOK marks the span as explicitly successful.
+	StatusOK
+
+	// StatusError marks the span as failed, the end record is logged with error level.
+	StatusError
+)
+
+func (c StatusCode) String() string {
+	switch c {
+	case StatusOK:
+		return "ok"
+	case StatusError:
+		return "error"
+	default:
+		return "unset"
+	}
+}
+
+// SetStatus sets the status of the span and an optional description which is logged as "error"
+// attribute for failed spans. The last call wins. It is safe to call SetStatus concurrently.
+func (s *Span) SetStatus(code StatusCode, description string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.status = code
+	s.statusDesc = description
+}
+
+// RecordError records an error in the span and marks the span as failed, nil errors are
+// ignored. All recorded errors are logged as "error" attribute on the end record. It is safe
+// to call RecordError concurrently.
+func (s *Span) RecordError(err error) {
+	if err == nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.errs = append(s.errs, err)
+	s.status = StatusError
+}
+
+// EndWithError records the error, when not nil, and ends the span. See End and RecordError.
+func (s *Span) EndWithError(err error, args ...any) {
+	s.RecordError(err)
+	s.end(args...)
+}
+
+// Status returns the status code of the span.
+func (s *Span) Status() StatusCode {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.status
+}
+
+// statusAttrs returns status and error attributes and log level for the end record.
+func (s *Span) statusAttrs() ([]slog.Attr, slog.Level) {
+	level := s.tracer.opts.level()
+	code, msg := s.statusDescription()
+	if code == StatusUnset {
+		return nil, level
+	}
+
+	attrs := []slog.Attr{slog.String("status", code.String())}
+	if code != StatusError {
+		return attrs, level
+	}
+
+	if msg != "" {
+		attrs = append(attrs, slog.String("error", msg))
+	}
+
+	return attrs, max(level, slog.LevelError)
+}
+
+// statusDescription returns the status code and the description joined with recorded errors
+// for failed spans.
+func (s *Span) statusDescription() (StatusCode, string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.status != StatusError {
+		return s.status, ""
+	}
+
+	msg := s.statusDesc
+	if err := errors.Join(s.errs...); err != nil {
+		if msg != "" {
+			msg += ": "
+		}
+		msg += err.Error()
+	}
+
+	return s.status, msg
+}
@@ -0,0 +1,97 @@
+package strc
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+
+	"github.com/osbuild/logging/pkg/collect"
+)
+
+func TestSpanStatus(t *testing.T) {
+	ch := collect.NewTestHandler(slog.LevelDebug, false, false, true)
+	tracer := NewTracer(slog.New(ch))
+
+	tests := []struct {
+		name   string
+		f      func(*Span)
+		status any
+		err    any
+		level  string
+	}{
+		{
+			name:  "unset",
+			f:     func(s *Span) { s.End() },
+			level: slog.LevelDebug.String(),
+		},
+		{
+			name:   "ok",
+			f:      func(s *Span) { s.SetStatus(StatusOK, ""); s.End() },
+			status: "ok",
+			level:  slog.LevelDebug.String(),
+		},
+		{
+			name:   "error status",
+			f:      func(s *Span) { s.SetStatus(StatusError, "bad input"); s.End() },
+			status: "error",
+			err:    "bad input",
+			level:  slog.LevelError.String(),
+		},
+		{
+			name:   "record error",
+			f:      func(s *Span) { s.RecordError(errors.New("e1")); s.RecordError(nil); s.End() },
+			status: "error",
+			err:    "e1",
+			level:  slog.LevelError.String(),
+		},
+		{
+			name:   "end with error",
+			f:      func(s *Span) { s.EndWithError(errors.New("e2"), "k", "v") },
+			status: "error",
+			err:    "e2",
+			level:  slog.LevelError.String(),
+		},
+		{
+			name:  "end with nil error",
+			f:     func(s *Span) { s.EndWithError(nil) },
+			level: slog.LevelDebug.String(),
+		},
+		{
+			name:   "description and errors",
+			f:      func(s *Span) { s.RecordError(errors.New("e3")); s.SetStatus(StatusError, "failed"); s.End() },
+			status: "error",
+			err:    "failed: e3",
+			level:  slog.LevelError.String(),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ch.Reset()
+			span, _ := tracer.Start(context.Background(), tt.name)
+			tt.f(span)
+
+			last := ch.Last()
+			group := last[SpanGroupName].(map[string]any)
+			if group["status"] != tt.status {
+				t.Errorf("status = %v, want %v", group["status"], tt.status)
+			}
+			if group["error"] != tt.err {
+				t.Errorf("error = %v, want %v", group["error"], tt.err)
+			}
+			if last[slog.LevelKey] != tt.level {
+				t.Errorf("level = %v, want %v", last[slog.LevelKey], tt.level)
+			}
+		})
+	}
+}
+
+func TestSpanFromContext(t *testing.T) {
+	tracer := NewTracer(slog.New(&NoopHandler{}))
+	span, ctx := tracer.Start(context.Background(), "test")
+
+	if SpanFromContext(ctx) != span {
+		t.Error("span not found in context")
+	}
+}
@@ -97,6 +97,12 @@ func (b *TraceBuilder) Process(r io.Reader, w io.Writer, format string) error {
 			return fmt.Errorf("duration is not an int: %s", scanner.Text())
 		}
 		span.Duration = time.Duration(dur) * time.Nanosecond
+		if status, ok := e["status"].(string); ok {
+			span.Status = status
+		}
+		if msg, ok := e["error"].(string); ok {
+			span.Error = msg
+		}
 		if b.FilterTraceID != "" && span.TraceID != b.FilterTraceID {
 			continue
 		}
@@ -132,7 +138,7 @@ func (b *TraceBuilder) Process(r io.Reader, w io.Writer, format string) error {
 				})
 			} else {
 				root.EachWithLevel(func(s *Span, lvl int) {
-					fmt.Fprintf(w, "%s%s.%s: %s (%s)\n", strings.Repeat(" ", lvl*2), s.TraceID, s.JoinNames("."), s.Duration.String(), s.Source)
+					fmt.Fprintf(w, "%s%s.%s: %s (%s)%s\n", strings.Repeat(" ", lvl*2), s.TraceID, s.JoinNames("."), s.Duration.String(), s.Source, s.StatusSuffix())
 				}, 0)
 			}
 		}
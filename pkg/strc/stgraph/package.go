@@ -14,4 +14,9 @@
 // - name: the span name
 // - source: the source file and line number
 // - dur: the duration of the span in nanoseconds
+// - status: optional span status, failed spans ("error") are highlighted
+// - error: optional error message of a failed span
+//
+// Start records are ignored, only records with the duration are processed. Therefore logs of
+// tracers in the single record mode (strc.WithSingleRecord) are processed the same way.
 package main
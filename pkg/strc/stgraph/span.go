@@ -13,6 +13,8 @@ type Span struct {
 	Name     string        `json:"name"`
 	Source   string        `json:"source"`
 	Duration time.Duration `json:"dur"`
+	Status   string        `json:"status"`
+	Error    string        `json:"error"`
 
 	// Children are the child spans of this span.
 	Children []*Span
@@ -54,3 +56,21 @@ func (s *Span) JoinNames(sep string) string {
 
 	return s.Parent.JoinNames(sep) + sep + s.Name
 }
+
+// Failed returns true when the span ended with error status.
+func (s *Span) Failed() bool {
+	return s.Status == "error"
+}
+
+// StatusSuffix returns a suffix for text output highlighting failed spans.
+func (s *Span) StatusSuffix() string {
+	if !s.Failed() {
+		return ""
+	}
+
+	if s.Error == "" {
+		return " FAILED"
+	}
+
+	return " FAILED: " + s.Error
+}
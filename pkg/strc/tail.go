@@ -0,0 +1,297 @@
+package strc
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+var _ slog.Handler = (*TailSamplingHandler)(nil)
+
+const (
+	// DefaultTailMaxRecords is the default maximum number of buffered span records.
+	DefaultTailMaxRecords = 10000
+
+	// DefaultTailTimeout is the default time after which unfinished traces are evaluated.
+	DefaultTailTimeout = time.Minute
+)
+
+// TailSamplingConfig is the configuration for TailSamplingHandler.
+type TailSamplingConfig struct {
+	// SlowThreshold keeps traces with at least one span longer than this duration. Zero
+	// disables this rule.
+	SlowThreshold time.Duration
+
+	// KeepErrors keeps traces with at least one error-level record, either a span record
+	// or a regular log record with the trace ID in its context. Regular log records are only
+	// taken into account while span records of the trace are held.
+	KeepErrors bool
+
+	// Percentage of the remaining traces which are kept (0-100). The decision is keyed on
+	// the trace ID, so multiple handlers with the same configuration agree.
+	Percentage float64
+
+	// MaxRecords is the maximum number of span records held in memory. When reached, the
+	// oldest trace is evaluated with the data available. Defaults to DefaultTailMaxRecords.
+	MaxRecords int
+
+	// Timeout is the time after which traces that never finished are evaluated with the
+	// data available. Defaults to DefaultTailTimeout.
+	Timeout time.Duration
+
+	// TracerOptions must match options of tracers logging through the handler, span group
+	// and parent ID names are used to recognize span records. See WithTracerOptions.
+	TracerOptions TracerOptions
+}
+
+// TailSamplingHandler is an slog.Handler wrapper which holds span records per trace ID in memory
+// until all spans of the trace in this process end. Then the whole trace is forwarded to the
+// next handler only when a rule from TailSamplingConfig matches, otherwise it is dropped. Regular
+// log records are always forwarded immediately.
+//
+// Tracers with WithSingleRecord do not log start records, such traces are complete when the
+// root span ends. Traces with a remote parent have no local root span and they are evaluated
+// when the timeout elapses.
+//
+// Timeouts are checked when new records arrive, call Flush to evaluate and forward all held
+// traces, for example before the application exits.
+type TailSamplingHandler struct {
+	next        slog.Handler
+	store       *tailStore
+	inSpanGroup bool
+}
+
+type tailEntry struct {
+	handler slog.Handler
+	ctx     context.Context
+	record  slog.Record
+}
+
+type tailTrace struct {
+	id      TraceID
+	created time.Time
+	entries []tailEntry
+	open    int
+	keep    bool
+	done    bool
+}
+
+type tailStore struct {
+	config  TailSamplingConfig
+	random  Sampler
+	mu      sync.Mutex
+	traces  map[TraceID]*tailTrace
+	order   []*tailTrace
+	records int
+}
+
+// NewTailSamplingHandler creates a new tail sampling handler forwarding to the next handler.
+func NewTailSamplingHandler(next slog.Handler, config TailSamplingConfig) *TailSamplingHandler {
+	if config.MaxRecords <= 0 {
+		config.MaxRecords = DefaultTailMaxRecords
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = DefaultTailTimeout
+	}
+
+	return &TailSamplingHandler{
+		next: next,
+		store: &tailStore{
+			config: config,
+			random: TraceIDRatioBased(config.Percentage / 100),
+			traces: make(map[TraceID]*tailTrace),
+		},
+	}
+}
+
+func (h *TailSamplingHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *TailSamplingHandler) Handle(ctx context.Context, r slog.Record) error {
+	tid := TraceIDFromContext(ctx)
+	if !h.inSpanGroup || tid == EmptyTraceID {
+		if tid != EmptyTraceID && h.store.config.KeepErrors && r.Level >= slog.LevelError {
+			h.store.markError(tid)
+		}
+		err := h.next.Handle(ctx, r)
+		return errors.Join(err, h.store.forward(h.store.expired()))
+	}
+
+	var dur time.Duration
+	var parent string
+	var finished, single, event bool
+	parentIDName := h.store.config.TracerOptions.parentIDName()
+	r.Attrs(func(a slog.Attr) bool {
+		switch a.Key {
+		case "dur":
+			finished = a.Value.Kind() == slog.KindDuration
+			dur = a.Value.Duration()
+		case "started":
+			single = a.Value.Kind() == slog.KindTime
+		case parentIDName:
+			parent = a.Value.String()
+		case "event":
+			event = true
+		}
+		return true
+	})
+
+	s := h.store
+	s.mu.Lock()
+	t := s.trace(tid)
+	t.entries = append(t.entries, tailEntry{handler: h.next, ctx: ctx, record: r.Clone()})
+	s.records++
+
+	if s.config.KeepErrors && r.Level >= slog.LevelError {
+		t.keep = true
+	}
+
+	var ready []*tailTrace
+	if finished {
+		if s.config.SlowThreshold > 0 && dur > s.config.SlowThreshold {
+			t.keep = true
+		}
+		if single {
+			// single record spans are never counted as open
+			if parent == EmptySpanID.ParentID() {
+				ready = append(ready, s.remove(t))
+			}
+		} else {
+			t.open--
+			if t.open <= 0 {
+				ready = append(ready, s.remove(t))
+			}
+		}
+	} else if !event {
+		t.open++
+	}
+	ready = append(ready, s.evict()...)
+	s.mu.Unlock()
+
+	return s.forward(ready)
+}
+
+// Flush evaluates all held traces and forwards the matching ones to the next handler,
+// unfinished traces are evaluated with the data available.
+func (h *TailSamplingHandler) Flush() error {
+	s := h.store
+	s.mu.Lock()
+	var ready []*tailTrace
+	for _, t := range s.order {
+		if !t.done {
+			ready = append(ready, s.remove(t))
+		}
+	}
+	s.order = nil
+	s.mu.Unlock()
+
+	return s.forward(ready)
+}
+
+func (h *TailSamplingHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &TailSamplingHandler{
+		next:        h.next.WithAttrs(attrs),
+		store:       h.store,
+		inSpanGroup: h.inSpanGroup,
+	}
+}
+
+func (h *TailSamplingHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+
+	return &TailSamplingHandler{
+		next:        h.next.WithGroup(name),
+		store:       h.store,
+		inSpanGroup: h.inSpanGroup || name == h.store.config.TracerOptions.spanGroupName(),
+	}
+}
+
+// trace returns existing or new trace, must be called with the lock held
+func (s *tailStore) trace(tid TraceID) *tailTrace {
+	t, ok := s.traces[tid]
+	if !ok {
+		t = &tailTrace{id: tid, created: time.Now()}
+		s.traces[tid] = t
+		s.order = append(s.order, t)
+	}
+
+	return t
+}
+
+// remove removes the trace from the store, must be called with the lock held
+func (s *tailStore) remove(t *tailTrace) *tailTrace {
+	t.done = true
+	s.records -= len(t.entries)
+	delete(s.traces, t.id)
+	return t
+}
+
+// evict removes traces over the memory cap or timeout, must be called with the lock held
+func (s *tailStore) evict() []*tailTrace {
+	var result []*tailTrace
+	deadline := time.Now().Add(-s.config.Timeout)
+	for len(s.order) > 0 {
+		t := s.order[0]
+		if !t.done && s.records <= s.config.MaxRecords && t.created.After(deadline) {
+			break
+		}
+
+		s.order = s.order[1:]
+		if !t.done {
+			result = append(result, s.remove(t))
+		}
+	}
+
+	// traces finished behind an unfinished head are skipped
+	if len(s.order) > 2*len(s.traces) {
+		order := make([]*tailTrace, 0, len(s.traces))
+		for _, t := range s.order {
+			if !t.done {
+				order = append(order, t)
+			}
+		}
+		s.order = order
+	}
+
+	return result
+}
+
+func (s *tailStore) expired() []*tailTrace {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.evict()
+}
+
+func (s *tailStore) markError(tid TraceID) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// traces which were already forwarded or dropped are not created again
+	if t, ok := s.traces[tid]; ok {
+		t.keep = true
+	}
+}
+
+// forward sends records of traces which matched a rule to their handlers
+func (s *tailStore) forward(traces []*tailTrace) error {
+	var errs []error
+	for _, t := range traces {
+		if !t.keep && !s.random.ShouldSample(context.Background(), t.id, "") {
+			continue
+		}
+
+		for _, e := range t.entries {
+			if err := e.handler.Handle(e.ctx, e.record); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
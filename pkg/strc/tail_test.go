@@ -0,0 +1,182 @@
+package strc
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/osbuild/logging/pkg/collect"
+)
+
+func tailTracer(config TailSamplingConfig) (*Tracer, *slog.Logger, *TailSamplingHandler, *collect.CollectorHandler) {
+	ch := collect.NewTestHandler(slog.LevelDebug, false, false, true)
+	th := NewTailSamplingHandler(ch, config)
+	logger := slog.New(NewMultiHandler(th))
+	return NewTracer(logger), logger, th, ch
+}
+
+func TestTailSamplingDropsHealthy(t *testing.T) {
+	tracer, logger, _, ch := tailTracer(TailSamplingConfig{SlowThreshold: time.Hour, KeepErrors: true})
+
+	span, ctx := tracer.Start(context.Background(), "root")
+	child, _ := tracer.Start(ctx, "child")
+	child.Event("event")
+	child.End()
+	logger.InfoContext(ctx, "regular message")
+	span.End()
+
+	if ch.Count() != 1 || !ch.Contains("regular message", slog.MessageKey) {
+		t.Errorf("unexpected records: %v", ch.All())
+	}
+}
+
+func TestTailSamplingKeepsSlow(t *testing.T) {
+	tracer, _, _, ch := tailTracer(TailSamplingConfig{SlowThreshold: time.Minute})
+
+	started := time.Now().Add(-time.Hour)
+	span, ctx := tracer.Start(context.Background(), "root")
+	child, _ := tracer.Start(ctx, "child", "started", started)
+	child.End()
+
+	if ch.Count() != 0 {
+		t.Fatalf("records forwarded before the trace ended: %v", ch.All())
+	}
+
+	span.End()
+	if ch.CountWith("span", "name") != 4 {
+		t.Errorf("unexpected records: %v", ch.All())
+	}
+}
+
+func TestTailSamplingKeepsErrors(t *testing.T) {
+	tracer, logger, _, ch := tailTracer(TailSamplingConfig{KeepErrors: true})
+
+	span, ctx := tracer.Start(context.Background(), "root")
+	logger.ErrorContext(ctx, "failure")
+	span.End()
+
+	if ch.Count() != 3 || ch.CountWith("span", "name") != 2 {
+		t.Errorf("unexpected records: %v", ch.All())
+	}
+}
+
+func TestTailSamplingErrorAfterTrace(t *testing.T) {
+	tracer, logger, th, _ := tailTracer(TailSamplingConfig{KeepErrors: true})
+
+	span, ctx := tracer.Start(context.Background(), "root")
+	span.End()
+	logger.ErrorContext(ctx, "late failure")
+
+	if len(th.store.traces) != 0 || len(th.store.order) != 0 {
+		t.Errorf("entry created for a finished trace: %v", th.store.traces)
+	}
+}
+
+func TestTailSamplingUnfinishedHead(t *testing.T) {
+	tracer, _, th, _ := tailTracer(TailSamplingConfig{})
+
+	_, _ = tracer.Start(context.Background(), "stuck")
+	for i := 0; i < 100; i++ {
+		span, _ := tracer.Start(context.Background(), "root")
+		span.End()
+	}
+
+	if len(th.store.traces) != 1 || len(th.store.order) > 2 {
+		t.Errorf("finished traces are held behind the unfinished head: %d", len(th.store.order))
+	}
+}
+
+func TestTailSamplingPercentage(t *testing.T) {
+	SetIDGenerator(NewSeededIDGenerator(AlphaFormat, 0))
+	tracer, _, _, ch := tailTracer(TailSamplingConfig{Percentage: 50})
+
+	for i := 0; i < 1000; i++ {
+		span, _ := tracer.Start(context.Background(), "root")
+		span.End()
+	}
+
+	kept := ch.Count() / 2
+	if kept < 400 || kept > 600 {
+		t.Errorf("kept %d traces out of 1000, want around 500", kept)
+	}
+}
+
+func TestTailSamplingMemoryCap(t *testing.T) {
+	tracer, _, th, ch := tailTracer(TailSamplingConfig{Percentage: 100, MaxRecords: 2})
+
+	// never finished traces
+	_, _ = tracer.Start(context.Background(), "one")
+	_, _ = tracer.Start(context.Background(), "two")
+	if ch.Count() != 0 {
+		t.Fatalf("unexpected records: %v", ch.All())
+	}
+
+	_, _ = tracer.Start(context.Background(), "three")
+	if ch.Count() != 1 || !ch.Contains("one", "span", "name") {
+		t.Fatalf("oldest trace was not evicted: %v", ch.All())
+	}
+
+	if err := th.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if ch.Count() != 3 {
+		t.Errorf("unexpected records after flush: %v", ch.All())
+	}
+}
+
+func TestTailSamplingTimeout(t *testing.T) {
+	tracer, logger, _, ch := tailTracer(TailSamplingConfig{Percentage: 100, Timeout: time.Millisecond})
+
+	_, _ = tracer.Start(context.Background(), "stuck")
+	time.Sleep(5 * time.Millisecond)
+	logger.Info("trigger")
+
+	if !ch.Contains("stuck", "span", "name") {
+		t.Errorf("timed out trace was not evaluated: %v", ch.All())
+	}
+}
+
+func TestTailSamplingSingleRecord(t *testing.T) {
+	ch := collect.NewTestHandler(slog.LevelDebug, false, false, true)
+	logger := slog.New(NewMultiHandler(NewTailSamplingHandler(ch, TailSamplingConfig{SlowThreshold: time.Minute})))
+	tracer := NewTracer(logger, WithSingleRecord())
+
+	span, ctx := tracer.Start(context.Background(), "root")
+	child, _ := tracer.Start(ctx, "child", WithStartTime(time.Now().Add(-time.Hour)))
+	child.Event("event")
+	child.End()
+
+	if ch.Count() != 0 {
+		t.Fatalf("records forwarded before the root span ended: %v", ch.All())
+	}
+
+	span.End()
+	if ch.CountWith("span", "name") != 2 {
+		t.Errorf("unexpected records: %v", ch.All())
+	}
+}
+
+func TestTailSamplingTracerOptions(t *testing.T) {
+	opts := TracerOptions{SpanGroupName: "trace", ParentIDName: "parent"}
+	ch := collect.NewTestHandler(slog.LevelDebug, false, false, true)
+	logger := slog.New(NewMultiHandler(NewTailSamplingHandler(ch, TailSamplingConfig{
+		SlowThreshold: time.Minute,
+		TracerOptions: opts,
+	})))
+	tracer := NewTracer(logger, WithTracerOptions(opts))
+
+	started := time.Now().Add(-time.Hour)
+	span, ctx := tracer.Start(context.Background(), "root")
+	child, _ := tracer.Start(ctx, "child", "started", started)
+	child.End()
+
+	if ch.Count() != 0 {
+		t.Fatalf("records forwarded before the trace ended: %v", ch.All())
+	}
+
+	span.End()
+	if ch.CountWith("trace", "name") != 4 {
+		t.Errorf("unexpected records: %v", ch.All())
+	}
+}
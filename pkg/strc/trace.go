@@ -5,10 +5,15 @@ import (
 	"fmt"
 	"log/slog"
 	"runtime"
+	"slices"
+	"sync"
 	"sync/atomic"
 	"time"
 )
 
+// Package variables below are defaults for all tracers, they are read on every call and must not
+// be changed at runtime. Use TracerOptions for per-tracer configuration.
+
 // Level is the log level used for trace logging.
 var Level slog.Level = slog.LevelDebug
 
@@ -33,9 +38,9 @@ func init() {
 	SetNoopLogger()
 }
 
-// SetLogger sets the logger for the package.
-func SetLogger(logger *slog.Logger) {
-	tracer.Store(NewTracer(logger))
+// SetLogger sets the logger for the package. Optional tracer options can be provided.
+func SetLogger(logger *slog.Logger, opts ...TracerOption) {
+	tracer.Store(NewTracer(logger, opts...))
 }
 
 // SetNoopLogger sets a no-op logger for the package.
@@ -46,13 +51,63 @@ func SetNoopLogger() {
 // Tracer is a wrapper for slog.Logger which logs into the initialized slog. Use strc.Start
 // and End package functions to use slog.Default() logger.
 type Tracer struct {
-	logger *slog.Logger
+	base       *slog.Logger
+	logger     *slog.Logger
+	ids        IDGenerator
+	sampler    Sampler
+	processors []SpanProcessor
+	profiling  *ProfilingConfig
+
+	// singleRecord suppresses start and event records, see WithSingleRecord
+	singleRecord bool
+
+	opts  TracerOptions
+	clock Clock
 }
 
+// TracerOption is an option for NewTracer.
+type TracerOption func(*Tracer)
+
 // NewTracer creates a new Tracer with the given logger. Use strc.Start and End package functions
 // to use slog.Default() logger.
-func NewTracer(logger *slog.Logger) *Tracer {
-	return &Tracer{logger: logger.WithGroup(SpanGroupName)}
+func NewTracer(logger *slog.Logger, opts ...TracerOption) *Tracer {
+	t := &Tracer{base: logger}
+	for _, opt := range opts {
+		opt(t)
+	}
+	t.logger = logger.WithGroup(t.opts.spanGroupName())
+	return t
+}
+
+// WithSingleRecord is a TracerOption that emits a single record per span. The start record is
+// not logged and events are buffered, the end record carries "started" time and "events" in
+// addition to the regular attributes. This halves the log volume and makes each span record
+// self-contained.
+//
+// Since events are buffered in memory until the span ends, avoid logging many events in
+// long-running spans.
+func WithSingleRecord() TracerOption {
+	return func(t *Tracer) {
+		t.singleRecord = true
+	}
+}
+
+// idGenerator returns the tracer ID generator or the package one.
+func (t *Tracer) idGenerator() IDGenerator {
+	if t.ids != nil {
+		return t.ids
+	}
+
+	return idGenerator.Load()
+}
+
+// sample returns the sampling decision for a new span.
+func (t *Tracer) sample(ctx context.Context, tid TraceID, name string) bool {
+	if t.sampler == nil {
+		return defaultSampler.ShouldSample(ctx, tid, name)
+	}
+
+	return t.sampler.ShouldSample(ctx, tid, name)
 }
 
 // Span represents a span of a trace. It is used to log events and end the span.
@@ -65,10 +120,29 @@ type Span struct {
 	sid     SpanID
 	args    []any
 	started time.Time
+	sampled bool
+	kind    SpanKind
+	links   []SpanContext
+
+	// recording is true when the span is sampled and there are span processors
+	recording bool
+	source    string
+	// fixedSource is true when the source was set via withSource
+	fixedSource bool
+	profile     *spanProfile
+	budget      *spanBudget
+
+	mu         sync.Mutex
+	status     StatusCode
+	statusDesc string
+	errs       []error
+	attrs      []slog.Attr
+	events     []EventData
 }
 
 // Start starts a new span with the given name and optional arguments. All arguments are present in
-// subsequent Event and End calls. Must call End to finish the span.
+// subsequent Event and End calls. Must call End to finish the span. The returned context carries
+// the span, see SpanFromContext.
 //
 //	span, ctx := strc.Start(ctx, "calculating something big")
 //	defer span.End()
@@ -78,26 +152,45 @@ type Span struct {
 // It immediately logs a message with the span name, span information in SpanGroupName and optional
 // arguments.
 //
-// Special argument named "started" of type time.Time can be used to set the start time of the span.
+// Typed StartOption values (WithStartTime, WithKind, WithLinks, WithAttributes) can be mixed
+// with arguments. Special argument named "started" of type time.Time can be used to set the start
+// time of the span too.
 func Start(ctx context.Context, name string, args ...any) (*Span, context.Context) {
 	return tracer.Load().Start(ctx, name, args...)
 }
 
 // Start starts a new span, see strc.Start for more information.
 func (t *Tracer) Start(ctx context.Context, name string, args ...any) (*Span, context.Context) {
+	opts, args := startOptions(args)
+
 	tid := TraceIDFromContext(ctx)
 	if tid == EmptyTraceID {
-		tid = NewTraceID()
+		tid = t.idGenerator().NewTraceID()
 		ctx = WithTraceID(ctx, tid)
 	}
 
-	sid := NewSpanID(ctx)
+	sampled := t.sample(ctx, tid, name)
+	flags := TraceFlagsFromContext(ctx) &^ FlagSampled
+	if sampled {
+		flags |= FlagSampled
+	}
+	ctx = WithTraceFlags(ctx, flags)
+
+	sid := t.idGenerator().NewSpanID(SpanIDFromContext(ctx))
 	ctx = WithSpanID(ctx, sid)
 
-	started := time.Now()
-	if p := findArgs[time.Time](args, "started"); p != nil {
+	started := opts.started
+	if p := findArgs[time.Time](args, "started"); p != nil && started.IsZero() {
 		started = *p
 	}
+	if started.IsZero() {
+		started = t.now()
+	}
+
+	var profile *spanProfile
+	if t.profiling != nil {
+		ctx, profile = t.startProfiling(ctx, name, tid)
+	}
 
 	span := &Span{
 		ctx:     ctx,
@@ -107,9 +200,37 @@ func (t *Tracer) Start(ctx context.Context, name string, args ...any) (*Span, co
 		sid:     sid,
 		args:    args,
 		started: started,
+		sampled: sampled,
+		kind:    opts.kind,
+		links:   opts.links,
+
+		recording:   sampled && len(t.processors) > 0,
+		profile:     profile,
+		fixedSource: opts.sourceSet,
+	}
+	ctx = WithSpan(ctx, span)
+
+	if opts.budget > 0 && sampled {
+		span.startBudget(opts.budget)
 	}
 
-	if !t.logger.Enabled(ctx, Level) {
+	if sampled && !t.opts.skipSource() {
+		if opts.sourceSet {
+			span.source = opts.source
+		} else if span.recording || t.singleRecord {
+			span.source = callerPtr(3)
+		}
+	}
+	if span.recording {
+		for _, p := range t.processors {
+			if sp, ok := p.(SpanStartProcessor); ok {
+				sp.OnStart(span)
+			}
+		}
+	}
+
+	level := t.opts.level()
+	if !sampled || t.singleRecord || !t.logger.Enabled(ctx, level) {
 		// Return early if logging is disabled with all arguments in case
 		// level changes during span lifetime. But we still need to return
 		// the span and context.
@@ -117,15 +238,18 @@ func (t *Tracer) Start(ctx context.Context, name string, args ...any) (*Span, co
 	}
 
 	// keep the order and capacity correct
-	attrs := make([]slog.Attr, 0, 4+1)
+	attrs := make([]slog.Attr, 0, 6+1)
 	attrs = append(attrs,
 		slog.String("name", name),
-		slog.String(SpanIDName, sid.ID()),
-		slog.String(ParentIDName, sid.ParentID()),
-		slog.String(TraceIDName, tid.String()),
+		slog.String(t.opts.spanIDName(), sid.ID()),
+		slog.String(t.opts.parentIDName(), sid.ParentID()),
+		slog.String(t.opts.traceIDName(), tid.String()),
 	)
+	attrs = span.appendKind(attrs, true)
 
-	if !SkipSource {
+	if span.source != "" {
+		attrs = append(attrs, slog.String(slog.SourceKey, span.source))
+	} else if !t.opts.skipSource() && !span.fixedSource {
 		attrs = append(attrs, slog.String(slog.SourceKey, callerPtr(3)))
 	}
 
@@ -133,7 +257,7 @@ func (t *Tracer) Start(ctx context.Context, name string, args ...any) (*Span, co
 	if len(args) > 0 {
 		logger = logger.With(args...)
 	}
-	logger.LogAttrs(ctx, Level, "span "+name+" started", attrs...)
+	t.log(ctx, logger, level, started, "span "+name+" started", attrs)
 
 	return span, ctx
 }
@@ -145,28 +269,55 @@ func (t *Tracer) Start(ctx context.Context, name string, args ...any) (*Span, co
 //
 // Special argument named "at" of type time.Time can be used to set the event time.
 func (s *Span) Event(name string, args ...any) {
-	if !s.tracer.logger.Enabled(s.ctx, Level) {
+	if !s.sampled {
 		return
 	}
 
-	at := time.Now()
+	at := s.tracer.now()
 	if p := findArgs[time.Time](args, "at"); p != nil {
 		at = *p
 	}
 
+	s.recordEvent(name, at, args)
+	if s.tracer.singleRecord {
+		return
+	}
+	s.logEvent(s.tracer.opts.level(), 3, name, at, args)
+}
+
+// recordEvent buffers the event for span processors and the single record mode.
+func (s *Span) recordEvent(name string, at time.Time, args []any) {
+	if s.recording || s.tracer.singleRecord {
+		s.mu.Lock()
+		s.events = append(s.events, EventData{Name: name, Time: at, Attributes: argsToAttrs(args)})
+		s.mu.Unlock()
+	}
+}
+
+// logEvent logs the event record, skip is the number of frames to skip for the source
+// attribute or zero for no source.
+func (s *Span) logEvent(level slog.Level, skip int, name string, at time.Time, args []any) {
+	opts := &s.tracer.opts
+	if !s.tracer.logger.Enabled(s.ctx, level) {
+		return
+	}
+
 	// keep the order and capacity correct
-	attrs := make([]slog.Attr, 0, 6+1)
+	attrs := make([]slog.Attr, 0, 7+1)
 	attrs = append(attrs,
 		slog.String("name", s.name),
-		slog.String(SpanIDName, s.sid.ID()),
-		slog.String(ParentIDName, s.sid.ParentID()),
-		slog.String(TraceIDName, s.tid.String()),
+		slog.String(opts.spanIDName(), s.sid.ID()),
+		slog.String(opts.parentIDName(), s.sid.ParentID()),
+		slog.String(opts.traceIDName(), s.tid.String()),
+	)
+	attrs = s.appendKind(attrs, false)
+	attrs = append(attrs,
 		slog.String("event", name),
 		slog.Duration("at", at.Sub(s.started)),
 	)
 
-	if !SkipSource {
-		attrs = append(attrs, slog.String(slog.SourceKey, callerPtr(2)))
+	if skip > 0 && !opts.skipSource() {
+		attrs = append(attrs, slog.String(slog.SourceKey, callerPtr(skip)))
 	}
 
 	logger := s.tracer.logger
@@ -176,7 +327,7 @@ func (s *Span) Event(name string, args ...any) {
 	if len(args) > 0 {
 		logger = logger.With(args...)
 	}
-	logger.LogAttrs(s.ctx, Level, "span "+s.name+" event "+name, attrs...)
+	s.tracer.log(s.ctx, logger, level, at, "span "+s.name+" event "+name, attrs)
 }
 
 // End finishes the span and logs the duration of the span. Optional arguments can be provided
@@ -185,40 +336,89 @@ func (s *Span) Event(name string, args ...any) {
 // It immediately logs a message with the span name, span information in SpanGroupName and
 // optional arguments.
 //
+// When the span failed (see RecordError and SetStatus), "status" and "error" attributes are added
+// and the record is logged with error level.
+//
+// Attributes set via SetAttributes are added to the end record.
+//
 // Special argument named "finished" of type time.Time can be used to set the finish time of the span.
 func (s *Span) End(args ...any) {
-	if !s.tracer.logger.Enabled(s.ctx, Level) {
+	s.end(args...)
+}
+
+func (s *Span) end(args ...any) {
+	if s.profile != nil {
+		s.profile.end()
+	}
+
+	if !s.sampled {
 		return
 	}
 
-	finished := time.Now()
+	finished := s.tracer.now()
 	if p := findArgs[time.Time](args, "finished"); p != nil {
 		finished = *p
 	}
+
+	if s.budget != nil {
+		s.endBudget(finished)
+	}
+
+	if s.recording {
+		data := s.data(finished, args)
+		for _, p := range s.tracer.processors {
+			p.OnEnd(data)
+		}
+	}
+
+	opts := &s.tracer.opts
+	statusAttrs, level := s.statusAttrs()
+	if !s.tracer.logger.Enabled(s.ctx, level) {
+		return
+	}
 	dur := finished.Sub(s.started)
 
 	// keep the order and capacity correct
-	attrs := make([]slog.Attr, 0, 5+1)
+	attrs := make([]slog.Attr, 0, 9+len(statusAttrs)+1)
 	attrs = append(attrs,
 		slog.String("name", s.name),
-		slog.String(SpanIDName, s.sid.ID()),
-		slog.String(ParentIDName, s.sid.ParentID()),
-		slog.String(TraceIDName, s.tid.String()),
-		slog.Duration("dur", dur),
+		slog.String(opts.spanIDName(), s.sid.ID()),
+		slog.String(opts.parentIDName(), s.sid.ParentID()),
+		slog.String(opts.traceIDName(), s.tid.String()),
 	)
+	attrs = s.appendKind(attrs, true)
+	attrs = append(attrs, slog.Duration("dur", dur))
+	if s.tracer.singleRecord {
+		attrs = append(attrs, slog.Time("started", s.started))
+		if events := s.bufferedEvents(); len(events) > 0 {
+			attrs = append(attrs, slog.Any("events", events))
+		}
+	}
+	attrs = append(attrs, statusAttrs...)
 
-	if !SkipSource {
-		attrs = append(attrs, slog.String(slog.SourceKey, callerPtr(2)))
+	if s.source != "" && (s.tracer.singleRecord || s.fixedSource) {
+		attrs = append(attrs, slog.String(slog.SourceKey, s.source))
+	} else if !opts.skipSource() && !s.fixedSource {
+		attrs = append(attrs, slog.String(slog.SourceKey, callerPtr(3)))
 	}
 
 	logger := s.tracer.logger
-	if len(s.args) > 0 {
-		logger = logger.With(s.args...)
+	spanArgs := s.args
+	if s.tracer.singleRecord && findArgs[any](spanArgs, "started") != nil {
+		// the start time is already logged, do not duplicate the "started" argument
+		spanArgs = convertToAny(slices.DeleteFunc(argsToAttrs(spanArgs), func(a slog.Attr) bool {
+			return a.Key == "started"
+		}))
+	}
+	if extra := s.attributes(); len(extra) > 0 {
+		logger = logger.With(convertToAny(UniqAttrs(append(argsToAttrs(spanArgs), extra...)))...)
+	} else if len(spanArgs) > 0 {
+		logger = logger.With(spanArgs...)
 	}
 	if len(args) > 0 {
 		logger = logger.With(args...)
 	}
-	logger.LogAttrs(s.ctx, Level, fmt.Sprintf("span %s finished in %v", s.name, dur), attrs...)
+	s.tracer.log(s.ctx, logger, level, finished, fmt.Sprintf("span %s finished in %v", s.name, dur), attrs)
 }
 
 // TraceID returns the trace ID of the span.
@@ -226,6 +426,137 @@ func (s *Span) TraceID() TraceID {
 	return s.tid
 }
 
+// SpanContext returns the span context which can be used to link other spans to this one.
+func (s *Span) SpanContext() SpanContext {
+	return SpanContext{
+		TraceID: s.tid,
+		SpanID:  s.sid,
+		Flags:   TraceFlagsFromContext(s.ctx),
+		State:   TraceStateFromContext(s.ctx),
+	}
+}
+
+// Kind returns the kind of the span.
+func (s *Span) Kind() SpanKind {
+	return s.kind
+}
+
+// appendKind appends kind (unless internal) and optionally links attributes
+func (s *Span) appendKind(attrs []slog.Attr, withLinks bool) []slog.Attr {
+	if s.kind != SpanKindInternal {
+		attrs = append(attrs, slog.String("kind", s.kind.String()))
+	}
+	if withLinks && len(s.links) > 0 {
+		attrs = append(attrs, slog.Any("links", s.links))
+	}
+
+	return attrs
+}
+
+// IsSampled returns true when the span is sampled. Events and end of unsampled spans
+// are not logged.
+func (s *Span) IsSampled() bool {
+	return s.sampled
+}
+
+// SetAttributes adds attributes to the span which are only known after the span was started,
+// for example a job ID. They are logged on the end record together with the Start arguments,
+// when an attribute key is set multiple times, the last value wins. It is safe to call
+// SetAttributes concurrently.
+func (s *Span) SetAttributes(attrs ...slog.Attr) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.attrs = UniqAttrs(append(s.attrs, attrs...))
+}
+
+// data returns span data for span processors
+func (s *Span) data(finished time.Time, args []any) SpanData {
+	status, desc := s.statusDescription()
+
+	attrs := argsToAttrs(s.args)
+	attrs = append(attrs, s.attributes()...)
+	attrs = append(attrs, argsToAttrs(args)...)
+
+	s.mu.Lock()
+	events := slices.Clone(s.events)
+	s.mu.Unlock()
+
+	return SpanData{
+		TraceID:           s.tid,
+		SpanID:            s.sid,
+		Flags:             TraceFlagsFromContext(s.ctx),
+		Name:              s.name,
+		Kind:              s.kind,
+		Start:             s.started,
+		End:               finished,
+		Attributes:        UniqAttrs(attrs),
+		Events:            events,
+		Links:             s.links,
+		Status:            status,
+		StatusDescription: desc,
+		Source:            s.source,
+	}
+}
+
+// attributes returns a copy of attributes set via SetAttributes
+func (s *Span) attributes() []slog.Attr {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return slices.Clone(s.attrs)
+}
+
+// bufferedEvents returns events of the span for the single record mode, each event is a map
+// with "name", "at" (duration since the span start) and event arguments.
+func (s *Span) bufferedEvents() []map[string]any {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.events) == 0 {
+		return nil
+	}
+
+	events := make([]map[string]any, 0, len(s.events))
+	for _, e := range s.events {
+		m := attrsToMap(e.Attributes)
+		m["name"] = e.Name
+		m["at"] = e.Time.Sub(s.started)
+		events = append(events, m)
+	}
+
+	return events
+}
+
+// attrsToMap converts attributes to a map, groups are converted to nested maps.
+func attrsToMap(attrs []slog.Attr) map[string]any {
+	m := make(map[string]any, len(attrs)+2)
+	for _, a := range attrs {
+		v := a.Value.Resolve()
+		if v.Kind() == slog.KindGroup {
+			m[a.Key] = attrsToMap(v.Group())
+		} else {
+			m[a.Key] = v.Any()
+		}
+	}
+
+	return m
+}
+
+// argsToAttrs converts key-value pairs and slog.Attr arguments to attributes
+func argsToAttrs(args []any) []slog.Attr {
+	r := slog.Record{}
+	r.Add(args...)
+
+	attrs := make([]slog.Attr, 0, r.NumAttrs())
+	r.Attrs(func(a slog.Attr) bool {
+		attrs = append(attrs, a)
+		return true
+	})
+
+	return attrs
+}
+
 func callerPtr(skip int) string {
 	_, file, line, ok := runtime.Caller(skip)
 	if !ok {
@@ -3,8 +3,9 @@ package strc
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"log/slog"
-	"math/rand"
+	"reflect"
 	"regexp"
 	"strings"
 	"testing"
@@ -15,7 +16,7 @@ func TestLogTextHandler(t *testing.T) {
 	t.Setenv("TZ", "UTC")
 	var buf bytes.Buffer
 
-	src = rand.NewSource(0)
+	SetIDGenerator(NewSeededIDGenerator(AlphaFormat, 0))
 	l := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
 	SetLogger(l)
 
@@ -126,3 +127,105 @@ func TestFindArgsAttrs(t *testing.T) {
 		})
 	}
 }
+
+func TestSetAttributes(t *testing.T) {
+	var got []slog.Attr
+	e := NewExportHandler(func(_ context.Context, attrs []slog.Attr) {
+		got = append(got, attrs...)
+	})
+	tracer := NewTracer(slog.New(e))
+
+	span, _ := tracer.Start(context.Background(), "job", "k1", "v1", "image", "unknown")
+	span.SetAttributes(slog.String("image", "rhel"), slog.Int("job_id", 1))
+	span.SetAttributes(slog.Int("job_id", 42))
+	span.Event("e")
+	got = nil
+	span.End()
+
+	values := make(map[string][]string)
+	for _, a := range got {
+		for _, ga := range a.Value.Group() {
+			values[ga.Key] = append(values[ga.Key], ga.Value.String())
+		}
+	}
+
+	for k, want := range map[string]string{"k1": "v1", "image": "rhel", "job_id": "42"} {
+		if v := values[k]; len(v) != 1 || v[0] != want {
+			t.Errorf("attribute %s: got %v, want %s", k, v, want)
+		}
+	}
+}
+
+func TestSingleRecord(t *testing.T) {
+	buf := &bytes.Buffer{}
+	SetLogger(slog.New(slog.NewJSONHandler(buf, &slog.HandlerOptions{Level: slog.LevelDebug})), WithSingleRecord())
+	defer SetNoopLogger()
+
+	started := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	span, _ := Start(context.Background(), "job", "k1", "v1", WithStartTime(started))
+	span.Event("downloaded", "size", 42, "at", started.Add(time.Second))
+	span.End("finished", started.Add(2*time.Second))
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 1 {
+		t.Fatalf("expected a single record, got:\n%s", buf.String())
+	}
+
+	var record struct {
+		Msg  string `json:"msg"`
+		Span struct {
+			Name    string           `json:"name"`
+			Parent  string           `json:"parent"`
+			Dur     int64            `json:"dur"`
+			Started time.Time        `json:"started"`
+			K1      string           `json:"k1"`
+			Source  string           `json:"source"`
+			Events  []map[string]any `json:"events"`
+		} `json:"span"`
+	}
+	if err := json.Unmarshal([]byte(lines[0]), &record); err != nil {
+		t.Fatal(err)
+	}
+
+	s := record.Span
+	if record.Msg != "span job finished in 2s" || s.Name != "job" || s.Parent != "0000000" || s.K1 != "v1" {
+		t.Errorf("unexpected record %s", lines[0])
+	}
+	if s.Dur != int64(2*time.Second) || !s.Started.Equal(started) {
+		t.Errorf("unexpected duration or start time %s", lines[0])
+	}
+	if !strings.Contains(s.Source, "trace_test.go") {
+		t.Errorf("unexpected source %s", s.Source)
+	}
+	want := map[string]any{"name": "downloaded", "size": float64(42), "at": float64(time.Second)}
+	if len(s.Events) != 1 || !reflect.DeepEqual(s.Events[0], want) {
+		t.Errorf("unexpected events %v", s.Events)
+	}
+}
+
+func TestSingleRecordStartedArg(t *testing.T) {
+	buf := &bytes.Buffer{}
+	SetLogger(slog.New(slog.NewJSONHandler(buf, &slog.HandlerOptions{Level: slog.LevelDebug})), WithSingleRecord())
+	defer SetNoopLogger()
+
+	started := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	span, _ := Start(context.Background(), "typed", WithStartTime(started))
+	span.End()
+	span, _ = Start(context.Background(), "argument", "started", started)
+	span.End()
+	span, _ = Start(context.Background(), "none")
+	span.End()
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("expected 3 records, got:\n%s", buf.String())
+	}
+	for _, line := range lines {
+		if n := strings.Count(line, `"started":`); n != 1 {
+			t.Errorf("expected one started attribute, got %d in %s", n, line)
+		}
+	}
+	if !strings.Contains(lines[1], `"started":"2024-01-01T00:00:00Z"`) {
+		t.Errorf("unexpected start time in %s", lines[1])
+	}
+}
@@ -0,0 +1,128 @@
+package strc
+
+import (
+	"log/slog"
+)
+
+// TracerOptions is a per-tracer configuration, see WithTracerOptions and NewMultiHandlerOptions.
+// Zero values fall back to package variables (Level, SpanGroupName, TraceIDName, SpanIDName,
+// ParentIDName, SkipSource, TraceIDFieldKey and BuildIDFieldKey) which are read on every call.
+// Package variables must not be changed at runtime, set the fields instead.
+type TracerOptions struct {
+	// Level is the log level used for trace logging. Use slog.LevelVar to change the level
+	// at runtime. Defaults to Level.
+	Level slog.Leveler
+
+	// SpanGroupName is the group name used for span attributes. Defaults to SpanGroupName.
+	SpanGroupName string
+
+	// TraceIDName is the key name used for trace ID. Defaults to TraceIDName.
+	TraceIDName string
+
+	// SpanIDName is the key name used for span ID. Defaults to SpanIDName.
+	SpanIDName string
+
+	// ParentIDName is the key name used for parent span ID. Defaults to ParentIDName.
+	ParentIDName string
+
+	// SkipSource disables source logging. Source is also skipped when SkipSource package
+	// variable is set.
+	SkipSource bool
+
+	// TraceIDFieldKey is the key used to store the trace ID in the log record by MultiHandler.
+	// Defaults to TraceIDFieldKey.
+	TraceIDFieldKey string
+
+	// SkipTraceID disables the trace ID field added by MultiHandler.
+	SkipTraceID bool
+
+	// BuildIDFieldKey is the key used to store the git commit in the log record by MultiHandler.
+	// Defaults to BuildIDFieldKey.
+	BuildIDFieldKey string
+
+	// SkipBuildID disables the build ID field added by MultiHandler.
+	SkipBuildID bool
+}
+
+// WithTracerOptions is a TracerOption that sets per-tracer configuration. This allows multiple
+// differently configured tracers in one process, for example a library tracer and an
+// application tracer.
+//
+//	level := new(slog.LevelVar)
+//	tracer := strc.NewTracer(logger, strc.WithTracerOptions(strc.TracerOptions{
+//		Level:         level,
+//		SpanGroupName: "trace",
+//	}))
+func WithTracerOptions(opts TracerOptions) TracerOption {
+	return func(t *Tracer) {
+		t.opts = opts
+	}
+}
+
+func (o *TracerOptions) level() slog.Level {
+	if o.Level != nil {
+		return o.Level.Level()
+	}
+
+	return Level
+}
+
+func (o *TracerOptions) spanGroupName() string {
+	if o.SpanGroupName != "" {
+		return o.SpanGroupName
+	}
+
+	return SpanGroupName
+}
+
+func (o *TracerOptions) traceIDName() string {
+	if o.TraceIDName != "" {
+		return o.TraceIDName
+	}
+
+	return TraceIDName
+}
+
+func (o *TracerOptions) spanIDName() string {
+	if o.SpanIDName != "" {
+		return o.SpanIDName
+	}
+
+	return SpanIDName
+}
+
+func (o *TracerOptions) parentIDName() string {
+	if o.ParentIDName != "" {
+		return o.ParentIDName
+	}
+
+	return ParentIDName
+}
+
+func (o *TracerOptions) skipSource() bool {
+	return o.SkipSource || SkipSource
+}
+
+// traceIDFieldKey returns the trace ID field key or an empty string when disabled.
+func (o *TracerOptions) traceIDFieldKey() string {
+	if o.SkipTraceID {
+		return ""
+	}
+	if o.TraceIDFieldKey != "" {
+		return o.TraceIDFieldKey
+	}
+
+	return TraceIDFieldKey
+}
+
+// buildIDFieldKey returns the build ID field key or an empty string when disabled.
+func (o *TracerOptions) buildIDFieldKey() string {
+	if o.SkipBuildID {
+		return ""
+	}
+	if o.BuildIDFieldKey != "" {
+		return o.BuildIDFieldKey
+	}
+
+	return BuildIDFieldKey
+}
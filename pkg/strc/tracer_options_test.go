@@ -0,0 +1,78 @@
+package strc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestTracerOptions(t *testing.T) {
+	level := new(slog.LevelVar)
+	level.Set(slog.LevelInfo)
+	opts := TracerOptions{
+		Level:           level,
+		SpanGroupName:   "trace",
+		TraceIDName:     "tid",
+		SpanIDName:      "sid",
+		ParentIDName:    "pid",
+		SkipSource:      true,
+		TraceIDFieldKey: "app_trace_id",
+		SkipBuildID:     true,
+	}
+
+	appBuf := &bytes.Buffer{}
+	appHandler := slog.NewJSONHandler(appBuf, &slog.HandlerOptions{Level: slog.LevelDebug})
+	app := NewTracer(slog.New(NewMultiHandlerOptions(opts, nil, nil, appHandler)), WithTracerOptions(opts))
+
+	libBuf := &bytes.Buffer{}
+	lib := NewTracer(slog.New(NewMultiHandler(slog.NewJSONHandler(libBuf, &slog.HandlerOptions{Level: slog.LevelDebug}))))
+
+	span, ctx := app.Start(context.Background(), "app")
+	slog.New(NewMultiHandlerOptions(opts, nil, nil, appHandler)).InfoContext(ctx, "message")
+	libSpan, _ := lib.Start(ctx, "lib")
+	libSpan.End()
+	span.End()
+
+	lines := strings.Split(strings.TrimSpace(appBuf.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("expected 3 records, got:\n%s", appBuf.String())
+	}
+
+	var start map[string]any
+	if err := json.Unmarshal([]byte(lines[0]), &start); err != nil {
+		t.Fatal(err)
+	}
+	group, ok := start["trace"].(map[string]any)
+	if !ok || start["level"] != "INFO" || start["build_id"] != nil {
+		t.Fatalf("unexpected start record %s", lines[0])
+	}
+	for _, key := range []string{"tid", "sid", "pid"} {
+		if _, ok := group[key]; !ok {
+			t.Errorf("key %s not found in %s", key, lines[0])
+		}
+	}
+	if _, ok := group["source"]; ok {
+		t.Errorf("unexpected source in %s", lines[0])
+	}
+
+	if !strings.Contains(lines[1], `"app_trace_id":"`+span.TraceID().String()+`"`) {
+		t.Errorf("unexpected message record %s", lines[1])
+	}
+
+	// the library tracer uses package defaults
+	if !strings.Contains(libBuf.String(), `"level":"DEBUG","msg":"span lib started","build_id":"","span":{"name":"lib"`) {
+		t.Errorf("unexpected library records:\n%s", libBuf.String())
+	}
+
+	// level can be changed at runtime
+	appBuf.Reset()
+	level.Set(slog.LevelWarn)
+	span, _ = app.Start(context.Background(), "app")
+	span.End()
+	if strings.Count(appBuf.String(), `"level":"WARN"`) != 2 {
+		t.Errorf("unexpected records after level change:\n%s", appBuf.String())
+	}
+}
@@ -0,0 +1,193 @@
+package strc
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"sync"
+	"sync/atomic"
+)
+
+// TransportConfig is the configuration of Transport and TracingDoer. Captured bodies and headers
+// are logged at debug level via slog.Default().
+type TransportConfig struct {
+	// WithRequestBody logs the request body up to RequestBodyMaxSize.
+	WithRequestBody bool
+
+	// WithResponseBody logs the response body up to ResponseBodyMaxSize. The body is logged when
+	// it is closed by the caller.
+	WithResponseBody bool
+
+	// WithRequestHeaders logs request headers except HiddenRequestHeaders.
+	WithRequestHeaders bool
+
+	// WithResponseHeaders logs response headers except HiddenResponseHeaders.
+	WithResponseHeaders bool
+}
+
+// Transport is a http.RoundTripper that adds tracing to the request and response. For every
+// round trip, it starts a client span named after the method and host (for example
+// "GET example.com") with method, url, status_code and attempt attributes, propagates trace
+// headers and records errors. Client and server errors (4xx and 5xx) mark the span as failed.
+// The span ends when the response body is closed, the url attribute has no query string and
+// the span has no source because the caller is http.Client.
+//
+//	client := &http.Client{Transport: strc.NewTransport(http.DefaultTransport)}
+type Transport struct {
+	base   http.RoundTripper
+	config TransportConfig
+}
+
+// NewTransport returns a new Transport, http.DefaultTransport is used when base is nil.
+func NewTransport(base http.RoundTripper) *Transport {
+	return NewTransportWithConfig(base, TransportConfig{})
+}
+
+// NewTransportWithConfig returns a new Transport with body and header logging configuration.
+func NewTransportWithConfig(base http.RoundTripper, config TransportConfig) *Transport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	return &Transport{
+		base:   base,
+		config: config,
+	}
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return t.roundTrip(req, t.base.RoundTrip, "")
+}
+
+// attemptCounter counts HTTP client attempts, see WithAttemptCounter.
+type attemptCounter struct {
+	n atomic.Int64
+}
+
+// WithAttemptCounter returns a context which counts round trips made by Transport with it. Pass
+// it to all requests of one logical operation, for example retries, and every client span gets
+// the attempt attribute starting from 1. Redirects are counted as well.
+func WithAttemptCounter(ctx context.Context) context.Context {
+	return context.WithValue(ctx, attemptsKey, &attemptCounter{})
+}
+
+// roundTrip traces the round trip made by next, source is the span source or empty for none.
+// The span ends when the response body is closed, so it covers reading the body.
+func (t *Transport) roundTrip(req *http.Request, next func(*http.Request) (*http.Response, error), source string) (*http.Response, error) {
+	reqURL := redactedURL(req.URL)
+	args := []any{
+		WithKind(SpanKindClient),
+		withSource(source),
+		slog.String("method", req.Method),
+		slog.String("url", reqURL),
+	}
+	if counter, ok := req.Context().Value(attemptsKey).(*attemptCounter); ok {
+		args = append(args, slog.Int64("attempt", counter.n.Add(1)))
+	}
+	span, ctx := Start(req.Context(), req.Method+" "+req.URL.Host, args...)
+
+	// round trippers must not modify the original request
+	req = req.Clone(ctx)
+	AddTraceHeaders(ctx, req)
+
+	logger := slog.Default().WithGroup("client").With(
+		slog.String("method", req.Method),
+		slog.String("url", reqURL),
+	)
+
+	var reqBody *bodyReader
+	if t.config.WithRequestBody && req.Body != nil && req.Body != http.NoBody {
+		reqBody = newBodyReader(req.Body, RequestBodyMaxSize, true)
+		req.Body = reqBody
+	}
+
+	res, err := next(req)
+
+	if t.config.WithRequestBody || t.config.WithRequestHeaders {
+		var attrs []slog.Attr
+		attrs = append(attrs, slog.Int64("length", req.ContentLength))
+		if reqBody != nil {
+			attrs = append(attrs, slog.String("body", reqBody.body.String()))
+		}
+		if t.config.WithRequestHeaders {
+			attrs = append(attrs, headersAttr(req.Header, HiddenRequestHeaders))
+		}
+		logger.LogAttrs(ctx, slog.LevelDebug, "http client request", slog.Attr{Key: "request", Value: slog.GroupValue(attrs...)})
+	}
+
+	if err != nil {
+		span.RecordError(err)
+		span.End()
+		return nil, err
+	}
+
+	span.SetAttributes(slog.Int("status_code", res.StatusCode))
+	if res.StatusCode >= http.StatusBadRequest {
+		span.SetStatus(StatusError, http.StatusText(res.StatusCode))
+	}
+
+	var attrs []slog.Attr
+	logResponse := t.config.WithResponseBody || t.config.WithResponseHeaders
+	if logResponse {
+		attrs = append(attrs, slog.Int64("length", res.ContentLength), slog.Int("status", res.StatusCode))
+		if t.config.WithResponseHeaders {
+			attrs = append(attrs, headersAttr(res.Header, HiddenResponseHeaders))
+		}
+	}
+	end := func(body *bodyReader) {
+		if logResponse {
+			if body != nil {
+				attrs = append(attrs, slog.String("body", body.body.String()))
+			}
+			logger.LogAttrs(ctx, slog.LevelDebug, "http client response", slog.Attr{Key: "response", Value: slog.GroupValue(attrs...)})
+		}
+		span.End()
+	}
+
+	if res.Body == nil || res.Body == http.NoBody {
+		end(nil)
+		return res, nil
+	}
+
+	body := &responseBody{ReadCloser: res.Body, end: end}
+	if t.config.WithResponseBody {
+		body.capture = newBodyReader(res.Body, ResponseBodyMaxSize, true)
+		body.ReadCloser = body.capture
+	}
+	res.Body = body
+
+	return res, nil
+}
+
+// redactedURL returns the URL without password, query and fragment, they can carry secrets.
+func redactedURL(u *url.URL) string {
+	c := *u
+	c.RawQuery = ""
+	c.ForceQuery = false
+	c.Fragment = ""
+	c.RawFragment = ""
+	return c.Redacted()
+}
+
+// responseBody logs the response and ends the client span when it is closed.
+type responseBody struct {
+	io.ReadCloser
+	capture *bodyReader
+	end     func(body *bodyReader)
+	once    sync.Once
+}
+
+// implements io.Closer
+func (b *responseBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(func() {
+		b.end(b.capture)
+	})
+	return err
+}
+
+var _ http.RoundTripper = (*Transport)(nil)
+var _ io.ReadCloser = (*responseBody)(nil)
@@ -0,0 +1,168 @@
+package strc_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/osbuild/logging/pkg/collect"
+	"github.com/osbuild/logging/pkg/strc"
+)
+
+func TestTransport(t *testing.T) {
+	logHandler := collect.NewTestHandler(slog.LevelDebug, false, false, false)
+	strc.SetLogger(slog.New(strc.NewMultiHandler(logHandler)))
+	defer strc.SetNoopLogger()
+
+	// bodies and headers are logged via the default logger
+	buf := &bytes.Buffer{}
+	defaultLogger := slog.Default()
+	slog.SetDefault(slog.New(slog.NewJSONHandler(buf, &slog.HandlerOptions{Level: slog.LevelDebug})))
+	defer slog.SetDefault(defaultLogger)
+
+	var traceID strc.TraceID
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceID = strc.TraceIDFromRequest(r)
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("Set-Cookie", "secret")
+		if r.URL.Path == "/missing" {
+			w.WriteHeader(http.StatusNotFound)
+		}
+		_, _ = w.Write(body)
+	}))
+	defer srv.Close()
+
+	client := &http.Client{Transport: strc.NewTransportWithConfig(nil, strc.TransportConfig{
+		WithRequestBody:     true,
+		WithResponseBody:    true,
+		WithRequestHeaders:  true,
+		WithResponseHeaders: true,
+	})}
+
+	span, ctx := strc.Start(strc.WithAttemptCounter(context.Background()), "parent")
+	for _, path := range []string{"/", "/missing"} {
+		req, _ := http.NewRequestWithContext(ctx, http.MethodPost, srv.URL+path, strings.NewReader("hello"))
+		req.Header.Set("Authorization", "secret")
+		res, err := client.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, _ := io.ReadAll(res.Body)
+		res.Body.Close()
+		assert.Equal(t, "hello", string(body))
+		assert.Empty(t, req.Header.Get(strc.TraceHTTPHeaderName), "original request must not be modified")
+	}
+	span.End()
+
+	host := strings.TrimPrefix(srv.URL, "http://")
+	assert.Equal(t, span.TraceID(), traceID)
+	assert.True(t, logHandler.Contains("span POST "+host+" started", slog.MessageKey))
+	assert.True(t, logHandler.Contains("client", "span", "kind"))
+	assert.True(t, logHandler.Contains(int64(200), "span", "status_code"))
+	assert.True(t, logHandler.Contains(int64(404), "span", "status_code"))
+	assert.True(t, logHandler.Contains("error", "span", "status"))
+	assert.True(t, logHandler.Contains(int64(1), "span", "attempt"))
+	assert.True(t, logHandler.Contains(int64(2), "span", "attempt"))
+	assert.Equal(t, 2, strings.Count(buf.String(), `"request":{"length":5,"body":"hello","headers":{`))
+	assert.Equal(t, 4, strings.Count(buf.String(), `"body":"hello"`))
+	assert.Equal(t, 1, strings.Count(buf.String(), `"response":{"length":5,"status":404,"headers":{`))
+	assert.Contains(t, buf.String(), `"X-Strc-Trace-Id":["`+traceID.String()+`"]`)
+	assert.NotContains(t, buf.String(), "secret")
+}
+
+type errorDoer struct{}
+
+var errDoer = errors.New("connection refused")
+
+func (errorDoer) Do(*http.Request) (*http.Response, error) {
+	return nil, errDoer
+}
+
+func TestTracingDoerError(t *testing.T) {
+	logHandler := collect.NewTestHandler(slog.LevelDebug, false, false, false)
+	strc.SetLogger(slog.New(strc.NewMultiHandler(logHandler)))
+	defer strc.SetNoopLogger()
+
+	req := httptest.NewRequest(http.MethodGet, "http://example.com/", nil)
+	_, err := strc.NewTracingDoer(errorDoer{}).Do(req)
+
+	var doerErr *strc.DoerErr
+	assert.ErrorAs(t, err, &doerErr)
+	assert.ErrorIs(t, err, errDoer)
+	assert.True(t, logHandler.Contains("span GET example.com started", slog.MessageKey))
+	assert.True(t, logHandler.Contains("error", "span", "status"))
+	assert.True(t, logHandler.Contains("connection refused", "span", "error"))
+}
+
+func TestTransportSpanEndsOnClose(t *testing.T) {
+	// span and client records go to the same handler to check their order
+	logHandler := collect.NewTestHandler(slog.LevelDebug, false, false, false)
+	strc.SetLogger(slog.New(strc.NewMultiHandler(logHandler)))
+	defer strc.SetNoopLogger()
+	defaultLogger := slog.Default()
+	slog.SetDefault(slog.New(logHandler))
+	defer slog.SetDefault(defaultLogger)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("hello"))
+	}))
+	defer srv.Close()
+
+	client := &http.Client{Transport: strc.NewTransportWithConfig(nil, strc.TransportConfig{
+		WithResponseBody: true,
+	})}
+	res, err := client.Get(srv.URL + "/path?token=secret#fragment")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, 1, logHandler.Count(), "span must not end before the body is closed")
+
+	body, _ := io.ReadAll(res.Body)
+	res.Body.Close()
+	res.Body.Close()
+	assert.Equal(t, "hello", string(body))
+
+	records := logHandler.All()
+	if len(records) != 3 {
+		t.Fatalf("unexpected records: %v", records)
+	}
+	assert.Equal(t, "http client response", records[1][slog.MessageKey])
+	assert.Contains(t, fmt.Sprint(records[1]), "body:hello")
+	assert.True(t, strings.HasPrefix(records[2][slog.MessageKey].(string), "span GET "))
+	assert.True(t, logHandler.Contains(srv.URL+"/path", "span", "url"))
+	assert.False(t, logHandler.Contains(nil, "span", "source"), "transport spans have no source")
+	assert.NotContains(t, logHandler.String(), "secret")
+	assert.NotContains(t, logHandler.String(), "fragment")
+}
+
+type okDoer struct{}
+
+func (okDoer) Do(*http.Request) (*http.Response, error) {
+	return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil
+}
+
+func TestTracingDoerSource(t *testing.T) {
+	logHandler := collect.NewTestHandler(slog.LevelDebug, false, false, false)
+	strc.SetLogger(slog.New(strc.NewMultiHandler(logHandler)))
+	defer strc.SetNoopLogger()
+
+	req := httptest.NewRequest(http.MethodGet, "http://example.com/", nil)
+	if _, err := strc.NewTracingDoer(okDoer{}).Do(req); err != nil {
+		t.Fatal(err)
+	}
+
+	sources := logHandler.CollectWith("span", "source")
+	assert.Len(t, sources, 2)
+	for _, source := range sources {
+		assert.Contains(t, source, "transport_test.go")
+	}
+}
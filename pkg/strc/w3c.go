@@ -0,0 +1,291 @@
+package strc
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+const (
+	TraceparentHTTPHeaderName = "traceparent"
+	TracestateHTTPHeaderName  = "tracestate"
+
+	w3cTraceLength = 32 // 4bf92f3577b34da6a3ce929d0e0e4736
+	w3cSpanLength  = 16 // 00f067aa0ba902b7
+	w3cVersion     = "00"
+
+	// encodedMarker prefixes W3C IDs which were encoded from strc IDs
+	encodedMarker = "00"
+
+	// encodeAlphabet is used to map strc ID characters to bytes, each character is
+	// encoded as its index plus one so the encoded ID can never be all zeroes
+	encodeAlphabet = letterBytes + "0123456789-_"
+)
+
+// TraceFlags are W3C trace flags. Only the sampled flag is defined by the specification.
+type TraceFlags byte
+
+const (
+	// FlagSampled is the W3C sampled flag.
+	FlagSampled TraceFlags = 0x01
+)
+
+// Sampled returns true when the sampled flag is set.
+func (f TraceFlags) Sampled() bool {
+	return f&FlagSampled == FlagSampled
+}
+
+func (f TraceFlags) String() string {
+	return hex.EncodeToString([]byte{byte(f)})
+}
+
+// Precedence selects which header family wins when a request carries both X-Strc and W3C headers.
+// Only one family is used for a single request, see MiddlewareConfig.HeaderPrecedence and
+// ExtractWithPrecedence.
+type Precedence int
+
+const (
+	// PreferStrc uses X-Strc-Trace-ID and X-Strc-Span-ID headers when present.
+	PreferStrc Precedence = iota
+
+	// PreferW3C uses traceparent header when present.
+	PreferW3C
+)
+
+// W3C returns a 32 characters long W3C trace ID. Trace IDs generated by strc are encoded
+// with each character stored as one byte (its position in the alphabet of letters, digits,
+// dash and underscore starting from one) prefixed with a zero byte, which allows decoding
+// via TraceIDFromW3C. For example, "bqzcRlJahlbbBZH" is "0002111a032c0c2401080c02021c3422".
+// Trace IDs which are already in W3C format are returned unchanged. Returns an empty string
+// for EmptyTraceID or a trace ID that cannot be encoded.
+func (t TraceID) W3C() string {
+	if t == "" || t == EmptyTraceID {
+		return ""
+	}
+
+	if isW3CHex(string(t), w3cTraceLength) {
+		return string(t)
+	}
+
+	return encodeW3C(string(t), w3cTraceLength)
+}
+
+// TraceIDFromW3C converts W3C trace ID into TraceID. IDs created via TraceID.W3C are decoded
+// into the original strc format, all other valid IDs are kept as they are. Returns EmptyTraceID
+// when the ID is not valid.
+func TraceIDFromW3C(s string) TraceID {
+	if !isW3CHex(s, w3cTraceLength) {
+		return EmptyTraceID
+	}
+
+	if d, ok := decodeW3C(s); ok {
+		return TraceID(d)
+	}
+
+	return TraceID(s)
+}
+
+// W3C returns a 16 characters long W3C parent ID of the span (the ID part of the span). The
+// encoding is the same as for TraceID.W3C. Returns an empty string for EmptySpanID or a span
+// ID that cannot be encoded.
+func (s SpanID) W3C() string {
+	if s == "" || s == EmptySpanID {
+		return ""
+	}
+
+	id := s.ID()
+	if isW3CHex(id, w3cSpanLength) {
+		return id
+	}
+
+	return encodeW3C(id, w3cSpanLength)
+}
+
+// SpanIDFromW3C converts W3C parent ID into SpanID with empty parent. IDs created via
+// SpanID.W3C are decoded into the original strc format, all other valid IDs are kept as
+// they are. Returns EmptySpanID when the ID is not valid.
+func SpanIDFromW3C(s string) SpanID {
+	if !isW3CHex(s, w3cSpanLength) {
+		return EmptySpanID
+	}
+
+	if d, ok := decodeW3C(s); ok {
+		return SpanID(EmptySpanID.ID() + "." + d)
+	}
+
+	return SpanID(EmptySpanID.ID() + "." + s)
+}
+
+// Traceparent returns W3C traceparent header value for the given IDs and flags. Returns an
+// empty string when any of the IDs cannot be converted.
+func Traceparent(traceID TraceID, spanID SpanID, flags TraceFlags) string {
+	tid := traceID.W3C()
+	sid := spanID.W3C()
+	if tid == "" || sid == "" {
+		return ""
+	}
+
+	return w3cVersion + "-" + tid + "-" + sid + "-" + flags.String()
+}
+
+// ParseTraceparent parses W3C traceparent header value. Higher versions than 00 are accepted
+// as required by the specification, additional fields are ignored.
+func ParseTraceparent(s string) (TraceID, SpanID, TraceFlags, error) {
+	parts := strings.Split(strings.TrimSpace(s), "-")
+	if len(parts) < 4 {
+		return EmptyTraceID, EmptySpanID, 0, fmt.Errorf("%w: %q", ErrInvalidTraceparent, s)
+	}
+
+	version, tid, sid, fl := parts[0], parts[1], parts[2], parts[3]
+	if !isW3CHex(version, 2) || version == "ff" || (version == w3cVersion && len(parts) != 4) {
+		return EmptyTraceID, EmptySpanID, 0, fmt.Errorf("%w: unsupported version %q", ErrInvalidTraceparent, s)
+	}
+
+	if !isW3CHex(tid, w3cTraceLength) || !isW3CHex(sid, w3cSpanLength) || !isW3CHex(fl, 2) {
+		return EmptyTraceID, EmptySpanID, 0, fmt.Errorf("%w: %q", ErrInvalidTraceparent, s)
+	}
+
+	flags, _ := hex.DecodeString(fl)
+	return TraceIDFromW3C(tid), SpanIDFromW3C(sid), TraceFlags(flags[0]), nil
+}
+
+// ErrInvalidTraceparent is returned when traceparent header cannot be parsed.
+var ErrInvalidTraceparent = errors.New("invalid traceparent")
+
+// TraceFlagsFromContext returns trace flags from a context. When flags were not set, it returns
+// FlagSampled since all spans are recorded by default.
+func TraceFlagsFromContext(ctx context.Context) TraceFlags {
+	if flags, ok := traceFlagsFromContext(ctx); ok {
+		return flags
+	}
+
+	return FlagSampled
+}
+
+// WithTraceFlags returns a new context with trace flags.
+func WithTraceFlags(ctx context.Context, flags TraceFlags) context.Context {
+	return context.WithValue(ctx, traceFlagsKey, flags)
+}
+
+// TraceStateFromContext returns W3C tracestate from a context or an empty string.
+func TraceStateFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+
+	if v := ctx.Value(traceStateKey); v != nil {
+		return v.(string)
+	}
+
+	return ""
+}
+
+// WithTraceState returns a new context with W3C tracestate. The value is opaque and it is
+// propagated to outgoing requests unchanged.
+func WithTraceState(ctx context.Context, state string) context.Context {
+	return context.WithValue(ctx, traceStateKey, state)
+}
+
+// AddTraceparentHeader adds W3C traceparent and tracestate headers from context to a request.
+// If trace ID or span ID is not found in the context or if the request already has a traceparent
+// header, it does nothing.
+func AddTraceparentHeader(ctx context.Context, req *http.Request) {
+	if req.Header.Get(TraceparentHTTPHeaderName) != "" {
+		return
+	}
+
+	tp := Traceparent(TraceIDFromContext(ctx), SpanIDFromContext(ctx), TraceFlagsFromContext(ctx))
+	if tp == "" {
+		return
+	}
+
+	req.Header.Set(TraceparentHTTPHeaderName, tp)
+	if ts := TraceStateFromContext(ctx); ts != "" {
+		req.Header.Set(TracestateHTTPHeaderName, ts)
+	}
+}
+
+// AddTraceHeaders adds both X-Strc and W3C headers from context to a request.
+func AddTraceHeaders(ctx context.Context, req *http.Request) {
+	AddTraceIDHeader(ctx, req)
+	AddSpanIDHeader(ctx, req)
+	AddTraceparentHeader(ctx, req)
+}
+
+// TraceparentFromRequest parses W3C traceparent header from a request. Returns false when
+// the header is missing or invalid.
+func TraceparentFromRequest(req *http.Request) (TraceID, SpanID, TraceFlags, bool) {
+	return traceparentFromCarrier(HeaderCarrier(req.Header))
+}
+
+// useW3C returns true when W3C fields should be used for the carrier according to the
+// precedence.
+func useW3C(carrier TextMapCarrier, p Precedence) bool {
+	_, _, _, w3c := traceparentFromCarrier(carrier)
+	if !w3c {
+		return false
+	}
+
+	hasStrc := carrier.Get(TraceHTTPHeaderName) != ""
+	return !hasStrc || p == PreferW3C
+}
+
+func isW3CHex(s string, length int) bool {
+	if len(s) != length {
+		return false
+	}
+
+	zero := true
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return false
+		}
+		if c != '0' {
+			zero = false
+		}
+	}
+
+	// all zeroes is an invalid value (version is handled by the caller)
+	return !zero || length == 2
+}
+
+func encodeW3C(s string, length int) string {
+	if len(encodedMarker)+len(s)*2 != length {
+		return ""
+	}
+
+	b := make([]byte, len(s))
+	for i := 0; i < len(s); i++ {
+		idx := strings.IndexByte(encodeAlphabet, s[i])
+		if idx < 0 {
+			return ""
+		}
+		b[i] = byte(idx + 1)
+	}
+
+	return encodedMarker + hex.EncodeToString(b)
+}
+
+func decodeW3C(s string) (string, bool) {
+	if !strings.HasPrefix(s, encodedMarker) {
+		return "", false
+	}
+
+	b, err := hex.DecodeString(s[len(encodedMarker):])
+	if err != nil {
+		return "", false
+	}
+
+	for i := range b {
+		if b[i] == 0 || int(b[i]) > len(encodeAlphabet) {
+			return "", false
+		}
+		b[i] = encodeAlphabet[b[i]-1]
+	}
+
+	return string(b), true
+}
@@ -0,0 +1,168 @@
+package strc
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func TestW3CRoundTrip(t *testing.T) {
+	SetIDGenerator(NewSeededIDGenerator(AlphaFormat, 0))
+
+	for i := 0; i < 100; i++ {
+		tid := NewTraceID()
+		w := tid.W3C()
+		if len(w) != w3cTraceLength {
+			t.Fatalf("TraceID(%s).W3C() = %s want length %d", tid, w, w3cTraceLength)
+		}
+		if got := TraceIDFromW3C(w); got != tid {
+			t.Errorf("TraceIDFromW3C(%s) = %s want %s", w, got, tid)
+		}
+
+		sid := NewSpanID(context.Background())
+		w = sid.W3C()
+		if len(w) != w3cSpanLength {
+			t.Fatalf("SpanID(%s).W3C() = %s want length %d", sid, w, w3cSpanLength)
+		}
+		if got := SpanIDFromW3C(w); got.ID() != sid.ID() {
+			t.Errorf("SpanIDFromW3C(%s).ID() = %s want %s", w, got.ID(), sid.ID())
+		}
+	}
+}
+
+func TestW3CKnownValues(t *testing.T) {
+	if got := TraceID("bqzcRlJahlbbBZH").W3C(); got != "0002111a032c0c2401080c02021c3422" {
+		t.Errorf("unexpected W3C trace ID %s", got)
+	}
+	if got := TraceID("aaaaaaaaaaaaaaa").W3C(); got != "00010101010101010101010101010101" {
+		t.Errorf("unexpected W3C trace ID %s", got)
+	}
+	if got := SpanID("0000000.bqzcRlJ").W3C(); got != "0002111a032c0c24" {
+		t.Errorf("unexpected W3C span ID %s", got)
+	}
+
+	// foreign IDs are kept unchanged in both directions
+	foreignTrace := "4bf92f3577b34da6a3ce929d0e0e4736"
+	if got := TraceIDFromW3C(foreignTrace); got != TraceID(foreignTrace) || got.W3C() != foreignTrace {
+		t.Errorf("unexpected foreign trace ID %s", got)
+	}
+	foreignSpan := "00f067aa0ba902b7"
+	if got := SpanIDFromW3C(foreignSpan); got.ID() != foreignSpan || got.ParentID() != EmptySpanID.ID() || got.W3C() != foreignSpan {
+		t.Errorf("unexpected foreign span ID %s", got)
+	}
+
+	// empty and invalid IDs
+	if EmptyTraceID.W3C() != "" || EmptySpanID.W3C() != "" {
+		t.Error("empty IDs must not be converted")
+	}
+	if TraceID("short").W3C() != "" || TraceID("?qzcRlJahlbbBZH").W3C() != "" {
+		t.Error("invalid IDs must not be converted")
+	}
+	if TraceIDFromW3C("00000000000000000000000000000000") != EmptyTraceID {
+		t.Error("all zeroes trace ID must be invalid")
+	}
+	if SpanIDFromW3C("0000000000000000") != EmptySpanID {
+		t.Error("all zeroes span ID must be invalid")
+	}
+}
+
+func TestParseTraceparent(t *testing.T) {
+	tests := []struct {
+		in      string
+		tid     TraceID
+		sid     string
+		flags   TraceFlags
+		invalid bool
+	}{
+		{in: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", tid: "4bf92f3577b34da6a3ce929d0e0e4736", sid: "00f067aa0ba902b7", flags: FlagSampled},
+		{in: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00", tid: "4bf92f3577b34da6a3ce929d0e0e4736", sid: "00f067aa0ba902b7", flags: 0},
+		{in: "01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-future", tid: "4bf92f3577b34da6a3ce929d0e0e4736", sid: "00f067aa0ba902b7", flags: FlagSampled},
+		{in: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra", invalid: true},
+		{in: "ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", invalid: true},
+		{in: "00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01", invalid: true},
+		{in: "00-00000000000000000000000000000000-00f067aa0ba902b7-01", invalid: true},
+		{in: "00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01", invalid: true},
+		{in: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7", invalid: true},
+		{in: "", invalid: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			tid, sid, flags, err := ParseTraceparent(tt.in)
+			if tt.invalid {
+				if !errors.Is(err, ErrInvalidTraceparent) {
+					t.Fatalf("expected ErrInvalidTraceparent, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tid != tt.tid || sid.ID() != tt.sid || flags != tt.flags {
+				t.Errorf("got %s %s %s", tid, sid, flags)
+			}
+		})
+	}
+}
+
+func TestTraceparentRoundTrip(t *testing.T) {
+	SetIDGenerator(NewSeededIDGenerator(AlphaFormat, 0))
+	tid := NewTraceID()
+	sid := NewSpanID(WithSpanID(context.Background(), NewSpanID(context.Background())))
+
+	ctx := WithTraceID(context.Background(), tid)
+	ctx = WithSpanID(ctx, sid)
+	ctx = WithTraceFlags(ctx, 0)
+	ctx = WithTraceState(ctx, "vendor=value")
+
+	r := &http.Request{Header: http.Header{}}
+	AddTraceHeaders(ctx, r)
+
+	if r.Header.Get(TraceparentHTTPHeaderName) != Traceparent(tid, sid, 0) {
+		t.Errorf("unexpected traceparent header %q", r.Header.Get(TraceparentHTTPHeaderName))
+	}
+	if r.Header.Get(TracestateHTTPHeaderName) != "vendor=value" {
+		t.Errorf("unexpected tracestate header %q", r.Header.Get(TracestateHTTPHeaderName))
+	}
+
+	gotTID, gotSID, flags, ok := TraceparentFromRequest(r)
+	if !ok || gotTID != tid || gotSID.ID() != sid.ID() || flags.Sampled() {
+		t.Errorf("unexpected traceparent values %s %s %s", gotTID, gotSID, flags)
+	}
+}
+
+func TestHeaderPrecedence(t *testing.T) {
+	r := &http.Request{Header: http.Header{}}
+	r.Header.Set(TraceHTTPHeaderName, "LOlIxiHprrrvHqD")
+	r.Header.Set(SpanHTTPHeaderName, "VIPEcES.yuufaHI")
+	r.Header.Set(TraceparentHTTPHeaderName, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+
+	if tid := TraceIDFromRequest(r); tid != "LOlIxiHprrrvHqD" {
+		t.Errorf("unexpected trace ID %s", tid)
+	}
+	if sid := SpanIDFromRequest(r); sid != "VIPEcES.yuufaHI" {
+		t.Errorf("unexpected span ID %s", sid)
+	}
+
+	tid, sid := extractIDs(HeaderCarrier(r.Header), PreferW3C)
+	if tid != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Errorf("unexpected trace ID %s", tid)
+	}
+	if sid.ID() != "00f067aa0ba902b7" {
+		t.Errorf("unexpected span ID %s", sid)
+	}
+
+	// W3C headers are always used when strc headers are missing
+	r.Header.Del(TraceHTTPHeaderName)
+	r.Header.Del(SpanHTTPHeaderName)
+	if tid := TraceIDFromRequest(r); tid != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Errorf("unexpected trace ID %s", tid)
+	}
+
+	// invalid traceparent is ignored
+	r.Header.Set(TraceparentHTTPHeaderName, "invalid")
+	if tid := TraceIDFromRequest(r); tid != EmptyTraceID {
+		t.Errorf("unexpected trace ID %s", tid)
+	}
+}